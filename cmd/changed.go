@@ -4,9 +4,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 
+	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
 	helm_env "k8s.io/helm/pkg/helm/environment"
 
@@ -20,30 +23,47 @@ Examples:
   $ helm charts list-changed <path> <flags>
 
   flags:
+    --base 			string			The revision to compare against instead of remote/branch. Nothing is fetched.
     --branch 			string			The name of the branch used to identify changes. (default "master")
     --commit 			string          The commit used to identify changes. (default "HEAD")
-    --exclude-dirs 		strings   		List of (sub-)directories to exclude.
+    --diff-mode 		string			One of: three-dot (compare against the merge base), two-dot (compare against the base itself). (default "three-dot")
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --git-backend 		string			How to access the git repository. One of: exec, go. (default "exec")
+    --github-output 		bool			Also write the changed charts as job matrix to the file named by $GITHUB_OUTPUT.
+    --head 			string			The revision to compare instead of --commit.
+    --include-dependents	bool			Also list charts depending on a changed chart via a local (file://) dependency.
+    --include-removed 	bool			Also list charts that were removed or moved since the merge base.
+    --name-status 		bool			Also list the changed files of each chart with their status (added, modified, deleted, renamed).
+    --no-fetch 			bool			Do not fetch the remote branch but compare against the refs that exist locally.
     --only-path         bool     		Only output the chart path.
+    -o, --output 		string			Output format. One of: table, json, yaml, matrix. (default "table")
     --output-dir 		string      	If given, results will be written to file in this directory.
     --output-filename 	string			Filename to use for output. (default "results.txt")
+    --skip-library-charts	bool			Do not list library charts.
+    --roll-up-subcharts	bool			Report changes of subcharts for the outermost deployable chart containing them.
     --remote 			string          The name of the git remote used to identify changes. (default "origin)
+    --uncommitted 		string			Include uncommitted changes. One of: none, staged, all (staged, unstaged and untracked). (default "none")
 
 `
 
 type changedChartsCmd struct {
+	gitFlags
 	helmSettings *helm_env.EnvSettings
 
 	directory          string
 	excludeDirs        []string
 	outputDir          string
 	outputFilename     string
+	outputFormat       string
 	writeOnlyChartPath bool
 	writeOnlyChartName bool
 	isUseRelativePath  bool
-
-	remote,
-	branch,
-	commit string
+	includeDependents  bool
+	skipLibraryCharts  bool
+	nameStatus         bool
+	includeRemoved     bool
+	rollUpSubcharts    bool
+	githubOutput       bool
 }
 
 func newChangedChartsCmd() *cobra.Command {
@@ -87,6 +107,15 @@ func newChangedChartsCmd() *cobra.Command {
 			}
 			c.outputFilename = outputFileName
 
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat, outputFormatMatrix); err != nil {
+				return err
+			}
+			c.outputFormat = outputFormat
+
 			writeOnlyName, err := cmd.Flags().GetBool(flagWriteOnlyName)
 			if err != nil {
 				return err
@@ -105,35 +134,97 @@ func newChangedChartsCmd() *cobra.Command {
 			}
 			c.writeOnlyChartPath = v
 
-			return c.listChanged()
+			skipLibraryCharts, err := cmd.Flags().GetBool(flagSkipLibraryCharts)
+			if err != nil {
+				return err
+			}
+			c.skipLibraryCharts = skipLibraryCharts
+
+			return c.listChanged(cmd.Context())
 		},
 	}
 
 	addCommonFlags(cmd)
-	cmd.Flags().StringVarP(&c.remote, "remote", "", "origin", "The name of the git remote used to identify changes.")
-	cmd.Flags().StringVarP(&c.branch, "branch", "", "master", "The name of the branch used to identify changes.")
-	cmd.Flags().StringVarP(&c.commit, "commit", "", "HEAD", "The commit used to identify changes.")
+	cmd.Flags().BoolP(flagSkipLibraryCharts, "", false, "Do not list library charts.")
+	c.gitFlags.addFlags(cmd)
+	cmd.Flags().BoolVarP(&c.includeDependents, "include-dependents", "", false, "Also list charts depending on a changed chart via a local (file://) dependency.")
+	cmd.Flags().BoolVarP(&c.rollUpSubcharts, "roll-up-subcharts", "", false, "Report changes of subcharts for the outermost deployable chart containing them.")
+	cmd.Flags().BoolVarP(&c.includeRemoved, "include-removed", "", false, "Also list charts that were removed or moved since the merge base.")
+	cmd.Flags().BoolVarP(&c.nameStatus, "name-status", "", false, "Also list the changed files of each chart with their status (added, modified, deleted, renamed).")
+	cmd.Flags().Lookup(flagOutputFormat).Usage = "Output format. One of: table, json, yaml, matrix."
+	cmd.Flags().BoolVarP(&c.githubOutput, "github-output", "", false, "Also write the changed charts as job matrix to the file named by $GITHUB_OUTPUT.")
 
 	return cmd
 }
 
-func (c *changedChartsCmd) listChanged() error {
-	results, err := charts.ListChangedHelmChartsInFolder(c.directory, c.excludeDirs, c.remote, c.branch, c.commit, c.isUseRelativePath)
+func (c *changedChartsCmd) listChanged(ctx context.Context) error {
+	res, err := charts.ListChangedCharts(ctx, c.directory, charts.ChangedOptions{
+		ListOptions: charts.ListOptions{
+			ExcludeDirs:       c.excludeDirs,
+			UseRelativePath:   c.isUseRelativePath,
+			SkipLibraryCharts: c.skipLibraryCharts,
+		},
+		GitOptions:        c.gitOptions(),
+		Remote:            c.remote,
+		Branch:            c.branch,
+		Commit:            c.commit,
+		IncludeDependents: c.includeDependents,
+		RollUpSubcharts:   c.rollUpSubcharts,
+		IncludeRemoved:    c.includeRemoved,
+	})
 	if err != nil {
 		return err
 	}
+	printWarnings(res.Warnings)
+
+	results := res.Charts
+	removed := res.Removed
 
-	if len(results) == 0 {
+	var out string
+	switch {
+	case c.outputFormat == outputFormatMatrix:
+		out, err = formatMatrixOutput(results)
+		if err != nil {
+			return err
+		}
+	case isStructuredOutput(c.outputFormat):
+		out, err = formatStructuredOutput(c.outputFormat, changedOutput{
+			comparisonOutput: c.comparisonOutput(res.Base, res.Head),
+			Charts:           c.newChangedChartsOutput(results),
+			Removed:          newRemovedChartsOutput(removed),
+		})
+		if err != nil {
+			return err
+		}
+	case len(results) == 0 && len(removed) == 0:
 		fmt.Println("Nothing was changed.")
+		if c.githubOutput {
+			return writeGitHubOutput(results)
+		}
 		return nil
+	case (c.includeDependents || c.rollUpSubcharts || len(c.sharedFiles) > 0) && !c.writeOnlyChartPath && !c.writeOnlyChartName:
+		out = formatChangedTableOutput(results, c.tableHeader())
+	default:
+		out = FormatTableOutput(results, c.tableHeader(), c.writeOnlyChartPath, c.writeOnlyChartName)
+	}
+	if c.outputFormat == outputFormatTable && !c.writeOnlyChartPath && !c.writeOnlyChartName {
+		if len(removed) > 0 {
+			out += "\n\n" + formatRemovedTableOutput(removed)
+		}
+		if c.nameStatus {
+			out += "\n\n" + formatChangedFiles(results)
+		}
 	}
+	fmt.Println(out)
 
-	header := fmt.Sprintf("Compared to %s/%s:%s following charts were changed:", c.remote, c.branch, c.commit)
-	table := FormatTableOutput(results, header, c.writeOnlyChartPath, c.writeOnlyChartName)
-	fmt.Println(table)
+	if c.githubOutput {
+		if err := writeGitHubOutput(results); err != nil {
+			return err
+		}
+	}
 
 	if c.outputDir != "" {
-		return c.writeToFile(table)
+		return c.writeToFile(out)
 	}
 
 	return nil
@@ -149,3 +240,117 @@ func (c *changedChartsCmd) writeToFile(table string) error {
 	_, err = f.Write([]byte(table))
 	return err
 }
+
+func (c *changedChartsCmd) tableHeader() string {
+	return fmt.Sprintf("Compared to %s following charts were changed:", c.comparison())
+}
+
+func formatChangedTableOutput(results []*charts.HelmChart, header string) string {
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	table.AddRow(header)
+	table.AddRow("NAME", "VERSION", "PATH", "REASON")
+	for _, r := range results {
+		reason := string(r.Reason)
+		if r.Via != "" {
+			reason = fmt.Sprintf("%s via %s", r.Reason, r.Via)
+		}
+		table.AddRow(r.Name, r.Version, r.Path, reason)
+	}
+	return table.String()
+}
+
+func formatRemovedTableOutput(removed []*charts.RemovedHelmChart) string {
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	table.AddRow("The following charts were removed or moved:")
+	table.AddRow("NAME", "VERSION", "PATH", "STATUS")
+	for _, r := range removed {
+		status := "removed"
+		if r.IsMoved() {
+			status = fmt.Sprintf("moved to %s", r.MovedTo)
+		}
+		table.AddRow(r.Chart.Name, r.Chart.Version, r.Chart.Path, status)
+	}
+	return table.String()
+}
+
+type removedChartOutput struct {
+	chartOutput
+	Status  string `json:"status"`
+	MovedTo string `json:"movedTo,omitempty"`
+}
+
+func newRemovedChartsOutput(removed []*charts.RemovedHelmChart) []removedChartOutput {
+	if removed == nil {
+		return nil
+	}
+	res := make([]removedChartOutput, 0, len(removed))
+	for _, r := range removed {
+		o := removedChartOutput{
+			chartOutput: newChartsOutput([]*charts.HelmChart{r.Chart})[0],
+			Status:      "removed",
+			MovedTo:     r.MovedTo,
+		}
+		if r.IsMoved() {
+			o.Status = "moved"
+		}
+		res = append(res, o)
+	}
+	return res
+}
+
+func (c *changedChartsCmd) newChangedChartsOutput(results []*charts.HelmChart) []chartOutput {
+	res := newChartsOutput(results)
+	if !c.nameStatus {
+		return res
+	}
+	for i, r := range results {
+		res[i].Files = make([]changedFileOutput, 0, len(r.Files))
+		for _, f := range r.Files {
+			res[i].Files = append(res[i].Files, changedFileOutput{
+				Status:  string(f.Status),
+				Path:    f.Path,
+				OldPath: f.OldPath,
+			})
+		}
+	}
+	return res
+}
+
+// formatChangedFiles lists the changed files of each chart like git diff --name-status.
+func formatChangedFiles(results []*charts.HelmChart) string {
+	var b strings.Builder
+	b.WriteString("Changed files:")
+	for _, r := range results {
+		fmt.Fprintf(&b, "\n%s (%s):", r.Name, r.Path)
+		if len(r.Files) == 0 {
+			fmt.Fprintf(&b, "\n  %s", r.Reason)
+			if r.Via != "" {
+				fmt.Fprintf(&b, " via %s", r.Via)
+			}
+		}
+		for _, f := range r.Files {
+			fmt.Fprintf(&b, "\n  %s\t%s", fileChangeStatusLetter(f.Status), f.Path)
+			if f.OldPath != "" {
+				fmt.Fprintf(&b, " (from %s)", f.OldPath)
+			}
+		}
+	}
+	return b.String()
+}
+
+func fileChangeStatusLetter(status charts.FileChangeStatus) string {
+	switch status {
+	case charts.FileAdded:
+		return "A"
+	case charts.FileDeleted:
+		return "D"
+	case charts.FileRenamed:
+		return "R"
+	default:
+		return "M"
+	}
+}
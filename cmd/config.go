@@ -0,0 +1,325 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+const (
+	flagConfig = "config"
+
+	configSourceDefault = "default"
+	configSourceFile    = "config"
+	configSourceFlag    = "flag"
+)
+
+var configViewLongUsage = `
+Print the effective settings of all commands after applying the configuration file.
+
+The configuration file .helm-charts.yaml is read from the given folder or the root of the git repository.
+It provides defaults for the flags of all commands. Flags given on the command line take precedence.
+
+  defaults:
+    remote: origin
+    branch: main
+    exclude-dirs: [test, "**/examples"]
+  commands:
+    list-changed:
+      include-dependents: true
+  sharedFiles:
+  - paths: [global/values/*.yaml, regions/]
+    charts: [keystone, "openstack/*"]
+
+The sharedFiles section maps changed files outside of chart directories to the charts using them.
+
+Examples:
+  $ helm charts config view <path> <flags>
+
+  flags:
+    --command 			string			Only print the settings of the given command.
+    --config 			string			Path of the configuration file.
+    -o, --output 		string			Output format. One of: table, json, yaml. (default "table")
+`
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration file.",
+	}
+	cmd.AddCommand(newConfigViewCmd())
+	return cmd
+}
+
+type configViewCmd struct {
+	config *charts.Config
+	command,
+	outputFormat string
+}
+
+func newConfigViewCmd() *cobra.Command {
+	v := &configViewCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "view",
+		Long:         configViewLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(v.outputFormat); err != nil {
+				return err
+			}
+
+			cfg, err := loadConfig(cmd, args)
+			if err != nil {
+				return err
+			}
+			v.config = cfg
+
+			var commands []*cobra.Command
+			for _, c := range configurableCommands(cmd.Root()) {
+				if v.command == "" || c.Name() == v.command {
+					commands = append(commands, c)
+				}
+			}
+			if len(commands) == 0 {
+				return fmt.Errorf("unknown command %q", v.command)
+			}
+
+			return v.view(commands)
+		},
+	}
+
+	cmd.Flags().StringVarP(&v.command, "command", "", "", "Only print the settings of the given command.")
+	cmd.Flags().StringVarP(&v.outputFormat, flagOutputFormat, "o", outputFormatTable, "Output format. One of: table, json, yaml.")
+
+	return cmd
+}
+
+type configSetting struct {
+	Flag   string `json:"flag"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+type configViewOutput struct {
+	File        string                     `json:"file"`
+	Commands    map[string][]configSetting `json:"commands"`
+	SharedFiles []charts.SharedFileMapping `json:"sharedFiles,omitempty"`
+}
+
+func (v *configViewCmd) view(commands []*cobra.Command) error {
+	res := configViewOutput{
+		File:        v.config.Path,
+		Commands:    make(map[string][]configSetting, len(commands)),
+		SharedFiles: v.config.SharedFiles,
+	}
+	for _, c := range commands {
+		// The inherited flags are shared by all commands, so undo the configuration of the previous one.
+		if err := resetFlags(c.InheritedFlags()); err != nil {
+			return err
+		}
+		sources, err := applyConfig(c, v.config)
+		if err != nil {
+			return err
+		}
+
+		settings := make([]configSetting, 0)
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			if f.Name == "help" {
+				return
+			}
+			settings = append(settings, configSetting{
+				Flag:   f.Name,
+				Value:  f.Value.String(),
+				Source: sources[f.Name],
+			})
+		})
+		res.Commands[c.Name()] = settings
+	}
+
+	if isStructuredOutput(v.outputFormat) {
+		out, err := formatStructuredOutput(v.outputFormat, res)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 200
+	if res.File == "" {
+		table.AddRow("No configuration file found.")
+	} else {
+		table.AddRow("Using configuration file:", res.File)
+	}
+	table.AddRow("COMMAND", "FLAG", "VALUE", "SOURCE")
+	for _, c := range commands {
+		for _, s := range res.Commands[c.Name()] {
+			table.AddRow(c.Name(), s.Flag, s.Value, s.Source)
+		}
+	}
+	if len(res.SharedFiles) > 0 {
+		table.AddRow("")
+		table.AddRow("SHARED FILES", "CHARTS")
+		for _, m := range res.SharedFiles {
+			table.AddRow(strings.Join(m.Paths, ", "), strings.Join(m.Charts, ", "))
+		}
+	}
+	fmt.Println(table.String())
+	return nil
+}
+
+type configContextKey struct{}
+
+func withConfig(ctx context.Context, cfg *charts.Config) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, configContextKey{}, cfg)
+}
+
+// configFromContext returns the configuration loaded before running the command or nil.
+func configFromContext(ctx context.Context) *charts.Config {
+	if ctx == nil {
+		return nil
+	}
+	cfg, _ := ctx.Value(configContextKey{}).(*charts.Config)
+	return cfg
+}
+
+// configurableCommands returns the commands whose flags can be set in the configuration file.
+func configurableCommands(root *cobra.Command) []*cobra.Command {
+	var res []*cobra.Command
+	for _, c := range root.Commands() {
+		if c.Runnable() && c.Name() != "help" && c.Name() != "completion" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// loadConfig reads the configuration file given by the --config flag or found for the folder given as first argument.
+func loadConfig(cmd *cobra.Command, args []string) (*charts.Config, error) {
+	configFile, err := cmd.Flags().GetString(flagConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *charts.Config
+	if configFile != "" {
+		cfg, err = charts.LoadConfigFile(configFile)
+	} else {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		var folder string
+		folder, err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err = charts.LoadConfig(folder)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	flagsByCommand := make(map[string][]string)
+	for _, c := range configurableCommands(cmd.Root()) {
+		visitCommandFlags(c, func(f *pflag.Flag) {
+			flagsByCommand[c.Name()] = append(flagsByCommand[c.Name()], f.Name)
+		})
+	}
+	if err := cfg.Validate(flagsByCommand); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyConfig sets the flags of the command that were not given on the command line to the configured values.
+// It returns the source of the value of every flag.
+func applyConfig(cmd *cobra.Command, cfg *charts.Config) (map[string]string, error) {
+	sources := make(map[string]string)
+	var err error
+	visitCommandFlags(cmd, func(f *pflag.Flag) {
+		switch {
+		case f.Changed:
+			sources[f.Name] = configSourceFlag
+			return
+		case err != nil:
+			return
+		}
+
+		sources[f.Name] = configSourceDefault
+		v, ok := cfg.Lookup(cmd.Name(), f.Name)
+		if !ok {
+			return
+		}
+		if err = setFlagValue(f, v); err != nil {
+			err = fmt.Errorf("%s: invalid value for flag %q: %w", cfg.Path, f.Name, err)
+			return
+		}
+		sources[f.Name] = configSourceFile
+	})
+	return sources, err
+}
+
+// visitCommandFlags calls fn for the local flags of the command and the persistent flags inherited from its parents.
+// Unlike Flags, this includes the inherited flags before the command line of the command was parsed.
+func visitCommandFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	cmd.LocalFlags().VisitAll(fn)
+	cmd.InheritedFlags().VisitAll(fn)
+}
+
+// resetFlags sets the flags that were not given on the command line back to their default values.
+func resetFlags(flags *pflag.FlagSet) error {
+	var err error
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || err != nil {
+			return
+		}
+		if s, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if def := strings.Trim(f.DefValue, "[]"); def != "" {
+				values = strings.Split(def, ",")
+			}
+			err = s.Replace(values)
+			return
+		}
+		err = f.Value.Set(f.DefValue)
+	})
+	return err
+}
+
+// setFlagValue sets the value without marking the flag as changed, so that it is still distinguishable from command line flags.
+func setFlagValue(f *pflag.Flag, v any) error {
+	var values []string
+	switch t := v.(type) {
+	case []any:
+		for _, e := range t {
+			values = append(values, fmt.Sprint(e))
+		}
+	case nil:
+	default:
+		values = []string{fmt.Sprint(t)}
+	}
+
+	if s, ok := f.Value.(pflag.SliceValue); ok {
+		return s.Replace(values)
+	}
+	if len(values) != 1 {
+		return fmt.Errorf("expected a single value, got %v", v)
+	}
+	return f.Value.Set(values[0])
+}
@@ -6,7 +6,10 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
@@ -17,27 +20,47 @@ import (
 
 var findDuplicatesChartsLongUsage = `
 Plugin to find duplicate Helm charts in the given folder.
+By default charts with the same name are duplicates. With --by-content charts with identical or similar templates and
+values files are reported regardless of their names.
+
+Intentional duplicates can be listed in an allowlist file (default .helm-charts-duplicates.yaml in the given folder).
+With --fail-on-duplicates only duplicates not accepted by the allowlist fail.
+With --output junit every chart is a test case, which fails if the chart has a duplicate not accepted by the allowlist.
+
+  duplicates:
+  - name: keystone
+    paths: [eu-de-1/keystone, eu-nl-1/keystone]
+    reason: regional variants
 
 Examples:
   $ helm charts find-duplicates <path> <flags>
 
   flags:
-      --exclude-dirs				strings		  List of (sub-)directories to exclude.
+      --allowlist           string      File listing accepted duplicates. (default ".helm-charts-duplicates.yaml" in the given folder)
+      --by-content          bool        Compare the templates and values files instead of the chart names.
+      --exclude-dirs				strings		  Gitignore-style patterns of (sub-)directories to exclude.
       --only-path           bool   			Only output the chart path.
+  -o, --output              string   		Output format. One of: table, json, yaml, junit, sarif. (default "table")
       --output-dir		    	string   		If given, results will be written to file in this directory.
       --output-filename     string   		Filename to use for output. (default "results.txt")
-			--fail-on-duplicates	bool				Fail if duplicate charts are found.
+			--fail-on-duplicates	bool				Fail if duplicate charts not accepted by the allowlist are found.
+      --min-similarity      float       Minimum similarity score between 0 and 1 reported with --by-content. (default 0.8)
 `
 
 type findDuplicatesChartsCmd struct {
 	helmSettings *helm_env.EnvSettings
+	allowlist    *charts.DuplicatesAllowlist
 	folder,
+	allowlistFile,
 	outputDir,
-	outputFilename string
+	outputFilename,
+	outputFormat string
 	writeOnlyChartPath,
 	isUseRelativePath,
-	failOnDuplicates bool
-	excludeDirs []string
+	failOnDuplicates,
+	byContent bool
+	minSimilarity float64
+	excludeDirs   []string
 }
 
 func newFindDuplicatesChartsCmd() *cobra.Command {
@@ -81,6 +104,15 @@ func newFindDuplicatesChartsCmd() *cobra.Command {
 			}
 			l.outputFilename = outputFileName
 
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat, outputFormatJUnit, outputFormatSARIF); err != nil {
+				return err
+			}
+			l.outputFormat = outputFormat
+
 			writeOnlyPath, err := cmd.Flags().GetBool(flagWriteOnlyPath)
 			if err != nil {
 				return err
@@ -93,66 +125,382 @@ func newFindDuplicatesChartsCmd() *cobra.Command {
 			}
 			l.isUseRelativePath = useRelativePath
 
+			if err := l.loadAllowlist(); err != nil {
+				return err
+			}
+
+			if l.minSimilarity < 0 || l.minSimilarity > 1 {
+				return fmt.Errorf("invalid minimum similarity %v, must be between 0 and 1", l.minSimilarity)
+			}
+			if l.byContent {
+				return l.findSimilar()
+			}
 			return l.findDuplicates()
 		},
 	}
 
 	addCommonFlags(cmd)
-	cmd.Flags().BoolVarP(&l.failOnDuplicates, "fail-on-duplicates", "", false, "Fail if duplicate charts are found.")
+	cmd.Flags().Lookup(flagOutputFormat).Usage = "Output format. One of: table, json, yaml, junit, sarif."
+	cmd.Flags().BoolVarP(&l.failOnDuplicates, "fail-on-duplicates", "", false, "Fail if duplicate charts not accepted by the allowlist are found.")
+	cmd.Flags().StringVarP(&l.allowlistFile, "allowlist", "", "", "File listing accepted duplicates. Defaults to .helm-charts-duplicates.yaml in the given folder if it exists.")
+	cmd.Flags().BoolVarP(&l.byContent, "by-content", "", false, "Compare the templates and values files instead of the chart names.")
+	cmd.Flags().Float64VarP(&l.minSimilarity, "min-similarity", "", 0.8, "Minimum similarity score between 0 and 1 reported with --by-content.")
 
 	return cmd
 }
 
+func (l *findDuplicatesChartsCmd) loadAllowlist() error {
+	fileName := l.allowlistFile
+	if fileName == "" {
+		fileName = filepath.Join(l.folder, charts.DuplicatesAllowlistFileName)
+		if _, err := os.Stat(fileName); errors.Is(err, os.ErrNotExist) {
+			l.allowlist = &charts.DuplicatesAllowlist{}
+			return nil
+		}
+	}
+
+	allowlist, err := charts.LoadDuplicatesAllowlist(fileName)
+	if err != nil {
+		return err
+	}
+	l.allowlist = allowlist
+	return nil
+}
+
 func (l *findDuplicatesChartsCmd) findDuplicates() error {
-	results, err := charts.FindDuplicateChartsInFolder(l.folder, l.excludeDirs, l.isUseRelativePath)
+	groups, err := charts.FindDuplicateChartGroupsInFolder(l.folder, l.excludeDirs, l.isUseRelativePath)
 	if err != nil {
 		return err
 	}
+	l.allowlist.Apply(l.folder, groups)
 
-	if len(results) == 0 {
+	var out string
+	switch {
+	case l.outputFormat == outputFormatJUnit:
+		out, err = l.formatDuplicatesJUnitOutput(groups)
+		if err != nil {
+			return err
+		}
+	case l.outputFormat == outputFormatSARIF:
+		out, err = l.formatDuplicatesSARIFOutput(groups)
+		if err != nil {
+			return err
+		}
+	case isStructuredOutput(l.outputFormat):
+		out, err = formatStructuredOutput(l.outputFormat, newDuplicatesOutput(groups))
+		if err != nil {
+			return err
+		}
+	case len(groups) == 0:
 		fmt.Println("No duplicates found.")
 		return nil
+	default:
+		out = l.formatTableOutput(groups)
 	}
+	fmt.Println(out)
 
-	fmt.Println(l.formatTableOutput(results))
+	if l.outputDir != "" {
+		if err := l.writeToFile(out); err != nil {
+			return err
+		}
+	}
+
+	var unapproved []string
+	for _, g := range groups {
+		if !g.Accepted {
+			unapproved = append(unapproved, g.Name)
+		}
+	}
+	if l.failOnDuplicates && len(unapproved) > 0 {
+		return fmt.Errorf("found multiple helm charts with the same name: %s", strings.Join(unapproved, ", "))
+	}
+
+	return nil
+}
+
+func (l *findDuplicatesChartsCmd) formatTableOutput(groups []*charts.DuplicateGroup) string {
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	if !l.writeOnlyChartPath {
+		table.AddRow("The following duplicate charts were found:")
+		table.AddRow("NAME", "VERSION", "PATH", "STATUS")
+	}
+
+	for i, g := range groups {
+		if i > 0 && !l.writeOnlyChartPath {
+			table.AddRow("")
+		}
+		for _, r := range g.Charts {
+			if l.writeOnlyChartPath {
+				table.AddRow(r.Path)
+			} else {
+				table.AddRow(r.Name, r.Version, r.Path, duplicateStatus(g.Accepted, g.Reason))
+			}
+		}
+	}
+	return table.String()
+}
+
+func duplicateStatus(accepted bool, reason string) string {
+	switch {
+	case !accepted:
+		return "new"
+	case reason != "":
+		return fmt.Sprintf("accepted (%s)", reason)
+	default:
+		return "accepted"
+	}
+}
+
+type duplicateGroupOutput struct {
+	Name     string        `json:"name"`
+	Accepted bool          `json:"accepted"`
+	Reason   string        `json:"reason,omitempty"`
+	Charts   []chartOutput `json:"charts"`
+}
+
+func newDuplicatesOutput(groups []*charts.DuplicateGroup) duplicatesOutput {
+	res := duplicatesOutput{Groups: make([]duplicateGroupOutput, 0, len(groups))}
+	for _, g := range groups {
+		res.Groups = append(res.Groups, duplicateGroupOutput{
+			Name:     g.Name,
+			Accepted: g.Accepted,
+			Reason:   g.Reason,
+			Charts:   newChartsOutput(g.Charts),
+		})
+	}
+	return res
+}
+
+func (l *findDuplicatesChartsCmd) findSimilar() error {
+	results, err := charts.FindSimilarChartsInFolder(l.folder, l.excludeDirs, l.minSimilarity, l.isUseRelativePath)
+	if err != nil {
+		return err
+	}
+
+	var out string
+	switch {
+	case l.outputFormat == outputFormatJUnit:
+		out, err = l.formatSimilarJUnitOutput(results)
+		if err != nil {
+			return err
+		}
+	case l.outputFormat == outputFormatSARIF:
+		out, err = l.formatSimilarSARIFOutput(results)
+		if err != nil {
+			return err
+		}
+	case isStructuredOutput(l.outputFormat):
+		out, err = formatStructuredOutput(l.outputFormat, l.newSimilarOutput(results))
+		if err != nil {
+			return err
+		}
+	case len(results) == 0:
+		fmt.Println("No duplicates found.")
+		return nil
+	default:
+		out = l.formatSimilarTableOutput(results)
+	}
+	fmt.Println(out)
 
 	if l.outputDir != "" {
-		return l.writeToFile(results)
+		if err := l.writeToFile(out); err != nil {
+			return err
+		}
 	}
 
 	if l.failOnDuplicates {
-		return errors.New("found multiple helm charts with the same name")
+		for _, r := range results {
+			if !l.allowlist.IsAcceptedPair(l.folder, r.Charts) {
+				return errors.New("found helm charts with similar content")
+			}
+		}
 	}
 
 	return nil
 }
 
-func (l *findDuplicatesChartsCmd) formatTableOutput(results []*charts.HelmChart) string {
+func (l *findDuplicatesChartsCmd) formatSimilarTableOutput(results []*charts.SimilarCharts) string {
 	table := uitable.New()
 	table.MaxColWidth = 200
 
 	if !l.writeOnlyChartPath {
-		table.AddRow("The following charts were found:")
-		table.AddRow("NAME", "VERSION", "PATH")
+		table.AddRow("The following charts have similar content:")
+		table.AddRow("SCORE", "NAME", "PATH", "NAME", "PATH", "STATUS")
 	}
 
 	for _, r := range results {
 		if l.writeOnlyChartPath {
-			table.AddRow(r.Path)
+			table.AddRow(r.Charts[0].Path, r.Charts[1].Path)
 		} else {
-			table.AddRow(r.Name, r.Version, r.Path)
+			table.AddRow(formatSimilarityScore(r), r.Charts[0].Name, r.Charts[0].Path, r.Charts[1].Name, r.Charts[1].Path,
+				duplicateStatus(l.allowlist.IsAcceptedPair(l.folder, r.Charts), ""))
 		}
 	}
 	return table.String()
 }
 
-func (l *findDuplicatesChartsCmd) writeToFile(results []*charts.HelmChart) error {
+func formatSimilarityScore(s *charts.SimilarCharts) string {
+	if s.Identical {
+		return "identical"
+	}
+	return fmt.Sprintf("%.2f", s.Score)
+}
+
+type similarOutput struct {
+	Similar []similarChartsOutput `json:"similar"`
+}
+
+type similarChartsOutput struct {
+	Score     float64       `json:"score"`
+	Identical bool          `json:"identical"`
+	Accepted  bool          `json:"accepted"`
+	Charts    []chartOutput `json:"charts"`
+}
+
+func (l *findDuplicatesChartsCmd) newSimilarOutput(results []*charts.SimilarCharts) similarOutput {
+	res := similarOutput{Similar: make([]similarChartsOutput, 0, len(results))}
+	for _, r := range results {
+		res.Similar = append(res.Similar, similarChartsOutput{
+			Score:     math.Round(r.Score*1000) / 1000,
+			Identical: r.Identical,
+			Accepted:  l.allowlist.IsAcceptedPair(l.folder, r.Charts),
+			Charts:    newChartsOutput(r.Charts[:]),
+		})
+	}
+	return res
+}
+
+// formatDuplicatesJUnitOutput reports every chart in the folder as test case.
+// Charts with duplicates not accepted by the allowlist fail with the paths of the other charts with the same name.
+func (l *findDuplicatesChartsCmd) formatDuplicatesJUnitOutput(groups []*charts.DuplicateGroup) (string, error) {
+	all, err := charts.ListHelmChartsInFolder(l.folder, l.excludeDirs, l.isUseRelativePath)
+	if err != nil {
+		return "", err
+	}
+
+	groupByPath := make(map[string]*charts.DuplicateGroup)
+	for _, g := range groups {
+		for _, c := range g.Charts {
+			groupByPath[c.Path] = g
+		}
+	}
+
+	cases := make([]junitTestCase, 0, len(all))
+	for _, c := range all {
+		tc := newJUnitTestCase("find-duplicates", c)
+		if g, ok := groupByPath[c.Path]; ok {
+			var others []string
+			for _, d := range g.Charts {
+				if d.Path != c.Path {
+					others = append(others, d.Path)
+				}
+			}
+			if g.Accepted {
+				tc.SystemOut = newJUnitText(fmt.Sprintf("%s: %s", duplicateStatus(g.Accepted, g.Reason), strings.Join(others, ", ")))
+			} else {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("found %d other chart(s) named %s", len(others), g.Name),
+					Type:    "duplicate",
+					Text:    strings.Join(others, "\n"),
+				}
+			}
+		}
+		cases = append(cases, tc)
+	}
+	return formatJUnitOutput("find-duplicates", cases)
+}
+
+// formatSimilarJUnitOutput reports every chart in the folder as test case.
+// Charts with similar content not accepted by the allowlist fail with the paths and scores of the similar charts.
+func (l *findDuplicatesChartsCmd) formatSimilarJUnitOutput(results []*charts.SimilarCharts) (string, error) {
+	all, err := charts.ListHelmChartsInFolder(l.folder, l.excludeDirs, l.isUseRelativePath)
+	if err != nil {
+		return "", err
+	}
+
+	similarByPath := make(map[string][]string)
+	for _, r := range results {
+		if l.allowlist.IsAcceptedPair(l.folder, r.Charts) {
+			continue
+		}
+		score := formatSimilarityScore(r)
+		similarByPath[r.Charts[0].Path] = append(similarByPath[r.Charts[0].Path], fmt.Sprintf("%s (%s)", r.Charts[1].Path, score))
+		similarByPath[r.Charts[1].Path] = append(similarByPath[r.Charts[1].Path], fmt.Sprintf("%s (%s)", r.Charts[0].Path, score))
+	}
+
+	cases := make([]junitTestCase, 0, len(all))
+	for _, c := range all {
+		tc := newJUnitTestCase("find-duplicates", c)
+		if similar, ok := similarByPath[c.Path]; ok {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("found %d chart(s) with similar content", len(similar)),
+				Type:    "similar",
+				Text:    strings.Join(similar, "\n"),
+			}
+		}
+		cases = append(cases, tc)
+	}
+	return formatJUnitOutput("find-duplicates", cases)
+}
+
+// formatDuplicatesSARIFOutput reports every duplicate chart at the name in its Chart.yaml with the other charts of the
+// group as related locations. Duplicates accepted by the allowlist are reported as suppressed.
+func (l *findDuplicatesChartsCmd) formatDuplicatesSARIFOutput(groups []*charts.DuplicateGroup) (string, error) {
+	b := newSARIFBuilder(l.folder)
+	for _, g := range groups {
+		for _, c := range g.Charts {
+			var (
+				others  []string
+				related []sarifLocation
+			)
+			for _, d := range g.Charts {
+				if d.Path != c.Path {
+					others = append(others, d.Path)
+					related = append(related, b.chartFileLocation(d.Path, "Chart.yaml", "name"))
+				}
+			}
+
+			r := b.add(sarifRuleDuplicateName,
+				fmt.Sprintf("Chart %s is also defined in %s.", g.Name, strings.Join(others, ", ")),
+				b.chartFileLocation(c.Path, "Chart.yaml", "name"))
+			r.RelatedLocations = related
+			if g.Accepted {
+				r.suppress(g.Reason)
+			}
+		}
+	}
+	return b.format()
+}
+
+// formatSimilarSARIFOutput reports every pair of similar charts at the name in the Chart.yaml of the first chart.
+func (l *findDuplicatesChartsCmd) formatSimilarSARIFOutput(results []*charts.SimilarCharts) (string, error) {
+	b := newSARIFBuilder(l.folder)
+	for _, s := range results {
+		level := sarifLevelWarning
+		if s.Identical {
+			level = sarifLevelError
+		}
+		r := b.add(sarifRuleSimilarContent,
+			fmt.Sprintf("Chart %s has content similar to %s in %s (%s).", s.Charts[0].Name, s.Charts[1].Name, s.Charts[1].Path, formatSimilarityScore(s)),
+			b.chartFileLocation(s.Charts[0].Path, "Chart.yaml", "name"))
+		r.Level = level
+		r.RelatedLocations = []sarifLocation{b.chartFileLocation(s.Charts[1].Path, "Chart.yaml", "name")}
+		if l.allowlist.IsAcceptedPair(l.folder, s.Charts) {
+			r.suppress("")
+		}
+	}
+	return b.format()
+}
+
+func (l *findDuplicatesChartsCmd) writeToFile(out string) error {
 	f, err := charts.EnsureFileExists(l.outputDir, l.outputFilename)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	_, err = f.Write([]byte(l.formatTableOutput(results)))
+	_, err = f.Write([]byte(out))
 	return err
 }
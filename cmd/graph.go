@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+const (
+	graphFormatDOT     = "dot"
+	graphFormatMermaid = "mermaid"
+	graphFormatJSON    = "json"
+)
+
+var graphFormats = []string{graphFormatDOT, graphFormatMermaid, graphFormatJSON}
+
+var graphLongUsage = `
+Export the dependency graph of the Helm charts in the given folder.
+
+Examples:
+  $ helm charts graph <path> <flags>
+
+  flags:
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --format 			string			Graph format. One of: dot, mermaid, json. (default "dot")
+    --highlight-changed	bool			Highlight the charts that were changed compared to remote/branch:commit.
+    --output-dir 		string      	If given, results will be written to file in this directory.
+    --output-filename 	string			Filename to use for output. (default "results.txt")
+    --relative-path 	bool			Return chart path' relative to the given directory.
+
+  The flags --remote, --branch, --commit, --base, --head, --diff-mode, --uncommitted, --no-fetch and --git-backend are used with --highlight-changed.
+`
+
+type graphCmd struct {
+	gitFlags
+
+	folder,
+	format,
+	outputDir,
+	outputFilename string
+	excludeDirs []string
+	isUseRelativePath,
+	highlightChanged bool
+}
+
+func newGraphCmd() *cobra.Command {
+	g := &graphCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "graph",
+		Long:         graphLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			folder, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			g.folder = folder
+
+			if !slices.Contains(graphFormats, g.format) {
+				return fmt.Errorf("invalid graph format %q, must be one of %v", g.format, graphFormats)
+			}
+
+			excludeDirs, err := cmd.Flags().GetStringSlice(flagExcludeDirs)
+			if err != nil {
+				return err
+			}
+			g.excludeDirs = excludeDirs
+
+			outputDir, err := cmd.Flags().GetString(flagOutputDir)
+			if err != nil {
+				return err
+			}
+			g.outputDir = outputDir
+
+			outputFileName, err := cmd.Flags().GetString(flagOutputFileName)
+			if err != nil {
+				return err
+			}
+			g.outputFilename = outputFileName
+
+			useRelativePath, err := cmd.Flags().GetBool(flagUseRelativePath)
+			if err != nil {
+				return err
+			}
+			g.isUseRelativePath = useRelativePath
+
+			return g.graph(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "Gitignore-style patterns of (sub-)directories to exclude.")
+	cmd.Flags().StringP(flagOutputDir, "", "", "If given, results will be written to file in this directory.")
+	cmd.Flags().StringP(flagOutputFileName, "", "results.txt", "Filename to use for output.")
+	cmd.Flags().BoolP(flagUseRelativePath, "", false, "Return chart path' relative to the given directory.")
+	cmd.Flags().StringVarP(&g.format, "format", "", graphFormatDOT, "Graph format. One of: dot, mermaid, json.")
+	cmd.Flags().BoolVarP(&g.highlightChanged, "highlight-changed", "", false, "Highlight the charts that were changed compared to remote/branch:commit.")
+	g.gitFlags.addFlags(cmd)
+
+	return cmd
+}
+
+func (g *graphCmd) graph(ctx context.Context) error {
+	graph, warnings, err := charts.BuildDependencyGraph(ctx, g.folder, g.excludeDirs, g.isUseRelativePath)
+	if err != nil {
+		return err
+	}
+
+	if g.highlightChanged {
+		changed, err := charts.ListChangedCharts(ctx, g.folder, charts.ChangedOptions{
+			ListOptions: charts.ListOptions{
+				ExcludeDirs:     g.excludeDirs,
+				UseRelativePath: g.isUseRelativePath,
+			},
+			GitOptions: g.gitOptions(),
+			Remote:     g.remote,
+			Branch:     g.branch,
+			Commit:     g.commit,
+		})
+		if err != nil {
+			return err
+		}
+		warnings = append(warnings, changed.Warnings...)
+		graph.MarkChanged(changed.Charts)
+	}
+	printWarnings(warnings)
+
+	var out string
+	switch g.format {
+	case graphFormatMermaid:
+		out = formatMermaidGraph(graph)
+	case graphFormatJSON:
+		b, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = string(b)
+	default:
+		out = formatDOTGraph(graph)
+	}
+	fmt.Println(out)
+
+	if g.outputDir != "" {
+		return g.writeToFile(out)
+	}
+	return nil
+}
+
+func (g *graphCmd) writeToFile(out string) error {
+	f, err := charts.EnsureFileExists(g.outputDir, g.outputFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write([]byte(out))
+	return err
+}
+
+func graphNodeLabel(n *charts.GraphNode) string {
+	if n.External {
+		return fmt.Sprintf("%s\n%s", n.Name, n.Repository)
+	}
+	return fmt.Sprintf("%s %s\n%s", n.Name, n.Version, n.Path)
+}
+
+func formatDOTGraph(g *charts.DependencyGraph) string {
+	var b strings.Builder
+	b.WriteString("digraph charts {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := []string{"label=" + quoteDOT(graphNodeLabel(n))}
+		switch {
+		case n.Changed:
+			attrs = append(attrs, "style=filled", "fillcolor=orange")
+		case n.External:
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", quoteDOT(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		if e.Version != "" {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", quoteDOT(e.From), quoteDOT(e.To), quoteDOT(e.Version))
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s;\n", quoteDOT(e.From), quoteDOT(e.To))
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func quoteDOT(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func formatMermaidGraph(g *charts.DependencyGraph) string {
+	// Mermaid only allows simple node identifiers, so number the nodes.
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		label := strings.ReplaceAll(graphNodeLabel(n), "\n", "<br/>")
+		label = strings.ReplaceAll(label, `"`, "#quot;")
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, label)
+	}
+	for _, e := range g.Edges {
+		if e.Version != "" {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.From], strings.ReplaceAll(e.Version, "|", "#124;"), ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	b.WriteString("  classDef changed fill:#f96\n")
+	b.WriteString("  classDef external stroke-dasharray: 5 5\n")
+	for _, n := range g.Nodes {
+		switch {
+		case n.Changed:
+			fmt.Fprintf(&b, "  class %s changed\n", ids[n.ID])
+		case n.External:
+			fmt.Fprintf(&b, "  class %s external\n", ids[n.ID])
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+// outputFormatJUnit is supported by the commands checking charts.
+const outputFormatJUnit = "junit"
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut *junitText    `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// junitText keeps line breaks readable in the report.
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+func newJUnitText(text string) *junitText {
+	if text == "" {
+		return nil
+	}
+	return &junitText{Text: text}
+}
+
+// newJUnitTestCase returns a passing test case for the given chart.
+func newJUnitTestCase(suite string, c *charts.HelmChart) junitTestCase {
+	return junitTestCase{
+		Name:      fmt.Sprintf("%s (%s)", c.Name, c.Path),
+		ClassName: suite,
+	}
+}
+
+// formatJUnitOutput returns a JUnit XML report with a single test suite named after the command.
+func formatJUnitOutput(suite string, cases []junitTestCase) (string, error) {
+	s := junitTestSuite{
+		Name:      suite,
+		Tests:     len(cases),
+		TestCases: cases,
+	}
+	for _, c := range cases {
+		if c.Failure != nil {
+			s.Failures++
+		}
+	}
+
+	b, err := xml.MarshalIndent(junitTestSuites{
+		Name:     "helm-charts",
+		Tests:    s.Tests,
+		Failures: s.Failures,
+		Suites:   []junitTestSuite{s},
+	}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(b), nil
+}
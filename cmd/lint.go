@@ -0,0 +1,393 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/helm/pkg/lint/support"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+const (
+	exitCodeLintError   = 1
+	exitCodeLintWarning = 2
+)
+
+var lintChartsLongUsage = `
+Run helm lint for the Helm charts in the given folder concurrently.
+
+The exit code reflects the worst finding: 1 for errors, 2 for warnings, 0 otherwise.
+Charts whose Chart.yaml cannot be loaded are reported as errors instead of aborting.
+
+Examples:
+  $ helm charts lint <path> <flags>
+
+  flags:
+    --changed 			bool			Only lint the charts changed compared to remote/branch:commit.
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --namespace 		string			Namespace used to render the templates. (default "default")
+    -o, --output 		string			Output format. One of: table, json, yaml, junit, sarif. (default "table")
+    --output-dir 		string      	If given, results will be written to file in this directory.
+    --output-filename 	string			Filename to use for output. (default "results.txt")
+    --strict 			bool			Fail on missing values while rendering the templates.
+    --values 			string			Values file used to render the templates.
+    --workers 			int				Maximum number of charts linted concurrently. (default number of CPUs)
+
+  The flags --remote, --branch, --commit, --base, --head, --diff-mode, --uncommitted, --no-fetch and --git-backend are used with --changed.
+`
+
+type lintChartsCmd struct {
+	gitFlags
+
+	folder,
+	outputDir,
+	outputFilename,
+	outputFormat,
+	namespace,
+	valuesFile string
+	excludeDirs []string
+	workers     int
+	isUseRelativePath,
+	onlyChanged,
+	strict bool
+}
+
+func newLintChartsCmd() *cobra.Command {
+	l := &lintChartsCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "lint",
+		Long:         lintChartsLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			folder, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			l.folder = folder
+
+			excludeDirs, err := cmd.Flags().GetStringSlice(flagExcludeDirs)
+			if err != nil {
+				return err
+			}
+			l.excludeDirs = excludeDirs
+
+			outputDir, err := cmd.Flags().GetString(flagOutputDir)
+			if err != nil {
+				return err
+			}
+			l.outputDir = outputDir
+
+			outputFileName, err := cmd.Flags().GetString(flagOutputFileName)
+			if err != nil {
+				return err
+			}
+			l.outputFilename = outputFileName
+
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat, outputFormatJUnit, outputFormatSARIF); err != nil {
+				return err
+			}
+			l.outputFormat = outputFormat
+
+			useRelativePath, err := cmd.Flags().GetBool(flagUseRelativePath)
+			if err != nil {
+				return err
+			}
+			l.isUseRelativePath = useRelativePath
+
+			return l.lint(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "Gitignore-style patterns of (sub-)directories to exclude.")
+	cmd.Flags().StringP(flagOutputDir, "", "", "If given, results will be written to file in this directory.")
+	cmd.Flags().StringP(flagOutputFileName, "", "results.txt", "Filename to use for output.")
+	cmd.Flags().StringP(flagOutputFormat, "o", outputFormatTable, "Output format. One of: table, json, yaml, junit, sarif.")
+	cmd.Flags().BoolP(flagUseRelativePath, "", false, "Return chart path' relative to the given directory.")
+	cmd.Flags().BoolVarP(&l.onlyChanged, "changed", "", false, "Only lint the charts changed compared to remote/branch:commit.")
+	cmd.Flags().StringVarP(&l.namespace, "namespace", "", "default", "Namespace used to render the templates.")
+	cmd.Flags().StringVarP(&l.valuesFile, "values", "", "", "Values file used to render the templates.")
+	cmd.Flags().BoolVarP(&l.strict, "strict", "", false, "Fail on missing values while rendering the templates.")
+	cmd.Flags().IntVarP(&l.workers, "workers", "", runtime.NumCPU(), "Maximum number of charts linted concurrently.")
+	l.gitFlags.addFlags(cmd)
+
+	return cmd
+}
+
+func (l *lintChartsCmd) lint(ctx context.Context) error {
+	listOpts := charts.ListOptions{
+		ExcludeDirs:     l.excludeDirs,
+		UseRelativePath: l.isUseRelativePath,
+	}
+	var (
+		selected []*charts.HelmChart
+		invalid  []charts.Warning
+		err      error
+	)
+	if l.onlyChanged {
+		res, err := charts.ListChangedCharts(ctx, l.folder, charts.ChangedOptions{
+			ListOptions: listOpts,
+			GitOptions:  l.gitOptions(),
+			Remote:      l.remote,
+			Branch:      l.branch,
+			Commit:      l.commit,
+		})
+		if err != nil {
+			return err
+		}
+		selected, invalid = res.Charts, res.Warnings
+	} else {
+		res, err := charts.ListCharts(ctx, l.folder, listOpts)
+		if err != nil {
+			return err
+		}
+		selected, invalid = res.Charts, res.Warnings
+	}
+
+	var values []byte
+	if l.valuesFile != "" {
+		values, err = os.ReadFile(l.valuesFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	results, err := charts.LintHelmCharts(ctx, l.folder, selected, charts.LintOptions{
+		Values:    values,
+		Namespace: l.namespace,
+		Strict:    l.strict,
+		Workers:   l.workers,
+	})
+	if err != nil {
+		return err
+	}
+	results = append(results, l.newInvalidChartLintResults(invalid)...)
+
+	var out string
+	switch {
+	case l.outputFormat == outputFormatJUnit:
+		out, err = formatJUnitOutput("lint", newLintJUnitTestCases(results))
+		if err != nil {
+			return err
+		}
+	case l.outputFormat == outputFormatSARIF:
+		out, err = l.formatLintSARIFOutput(results)
+		if err != nil {
+			return err
+		}
+	case isStructuredOutput(l.outputFormat):
+		out, err = formatStructuredOutput(l.outputFormat, newLintOutput(results))
+		if err != nil {
+			return err
+		}
+	case len(results) == 0:
+		fmt.Println("No charts to lint.")
+		return nil
+	default:
+		out = formatLintReport(results)
+	}
+	fmt.Println(out)
+
+	if l.outputDir != "" {
+		if err := l.writeToFile(out); err != nil {
+			return err
+		}
+	}
+
+	return lintExitError(results)
+}
+
+func lintSeverityName(severity int) string {
+	switch severity {
+	case charts.LintSeverityError:
+		return "ERROR"
+	case charts.LintSeverityWarning:
+		return "WARNING"
+	case charts.LintSeverityInfo:
+		return "INFO"
+	default:
+		return "OK"
+	}
+}
+
+func formatLintReport(results []*charts.LintResult) string {
+	var (
+		b      strings.Builder
+		failed int
+	)
+	for _, r := range results {
+		fmt.Fprintf(&b, "==> Linting %s %s (%s): %s\n", r.Chart.Name, r.Chart.Version, r.Chart.Path, lintSeverityName(r.HighestSeverity))
+		for _, m := range r.Messages {
+			fmt.Fprintf(&b, "  [%s] %s: %s\n", lintSeverityName(m.Severity), m.Path, m.Err)
+		}
+		if r.HighestSeverity >= charts.LintSeverityError {
+			failed++
+		}
+	}
+	fmt.Fprintf(&b, "\n%d chart(s) linted, %d chart(s) failed", len(results), failed)
+	return b.String()
+}
+
+type lintOutput struct {
+	Charts []lintChartOutput `json:"charts"`
+}
+
+type lintChartOutput struct {
+	chartOutput
+	Severity string              `json:"severity"`
+	Messages []lintMessageOutput `json:"messages"`
+}
+
+type lintMessageOutput struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+func newLintOutput(results []*charts.LintResult) lintOutput {
+	res := lintOutput{Charts: make([]lintChartOutput, 0, len(results))}
+	for _, r := range results {
+		c := lintChartOutput{
+			chartOutput: newChartsOutput([]*charts.HelmChart{r.Chart})[0],
+			Severity:    lintSeverityName(r.HighestSeverity),
+			Messages:    make([]lintMessageOutput, 0, len(r.Messages)),
+		}
+		for _, m := range r.Messages {
+			c.Messages = append(c.Messages, lintMessageOutput{
+				Severity: lintSeverityName(m.Severity),
+				Path:     m.Path,
+				Message:  m.Err.Error(),
+			})
+		}
+		res.Charts = append(res.Charts, c)
+	}
+	return res
+}
+
+// newInvalidChartLintResults reports the charts that could not be loaded as lint errors of their Chart.yaml.
+func (l *lintChartsCmd) newInvalidChartLintResults(invalid []charts.Warning) []*charts.LintResult {
+	res := make([]*charts.LintResult, 0, len(invalid))
+	for _, w := range invalid {
+		chartPath := w.Path
+		if l.isUseRelativePath {
+			if relPath, err := filepath.Rel(l.folder, w.Path); err == nil {
+				chartPath = relPath
+			}
+		}
+		res = append(res, &charts.LintResult{
+			Chart: &charts.HelmChart{Name: filepath.Base(w.Path), Path: chartPath},
+			Messages: []support.Message{{
+				Severity: charts.LintSeverityError,
+				Path:     "Chart.yaml",
+				Err:      w.Err,
+			}},
+			HighestSeverity: charts.LintSeverityError,
+		})
+	}
+	return res
+}
+
+// newLintJUnitTestCases returns a test case per chart, which fails on lint errors.
+// Warnings and infos do not fail the test case but are reported as its output.
+func newLintJUnitTestCases(results []*charts.LintResult) []junitTestCase {
+	cases := make([]junitTestCase, 0, len(results))
+	for _, r := range results {
+		tc := newJUnitTestCase("lint", r.Chart)
+		var messages strings.Builder
+		for _, m := range r.Messages {
+			fmt.Fprintf(&messages, "[%s] %s: %s\n", lintSeverityName(m.Severity), m.Path, m.Err)
+		}
+		if r.HighestSeverity >= charts.LintSeverityError {
+			tc.Failure = &junitFailure{
+				Message: "linting failed",
+				Type:    lintSeverityName(r.HighestSeverity),
+				Text:    messages.String(),
+			}
+		} else {
+			tc.SystemOut = newJUnitText(messages.String())
+		}
+		cases = append(cases, tc)
+	}
+	return cases
+}
+
+// formatLintSARIFOutput reports every lint message at the file it refers to or, if it refers to a directory like
+// templates/ or a missing file, at the Chart.yaml. Errors in the Chart.yaml are reported as invalid chart metadata.
+func (l *lintChartsCmd) formatLintSARIFOutput(results []*charts.LintResult) (string, error) {
+	b := newSARIFBuilder(l.folder)
+	for _, r := range results {
+		for _, m := range r.Messages {
+			var rule sarifRule
+			switch {
+			case m.Severity >= charts.LintSeverityError && m.Path == "Chart.yaml":
+				rule = sarifRuleInvalidChartMetadata
+			case m.Severity >= charts.LintSeverityError:
+				rule = sarifRuleLintError
+			case m.Severity == charts.LintSeverityWarning:
+				rule = sarifRuleLintWarning
+			default:
+				rule = sarifRuleLintInfo
+			}
+			msg := m.Err.Error()
+			loc := b.chartFileLocation(r.Chart.Path, "Chart.yaml", "")
+			if l.isRegularFile(r.Chart.Path, m.Path) {
+				loc = withErrorLine(b.chartFileLocation(r.Chart.Path, m.Path, ""), msg)
+			}
+			b.add(rule, fmt.Sprintf("%s: %s", r.Chart.Name, msg), loc)
+		}
+	}
+	return b.format()
+}
+
+// isRegularFile checks if the path reported by helm lint relative to the chart is a file that can be annotated.
+func (l *lintChartsCmd) isRegularFile(chartPath, file string) bool {
+	if file == "" {
+		return false
+	}
+	if !filepath.IsAbs(chartPath) {
+		chartPath = filepath.Join(l.folder, chartPath)
+	}
+	fi, err := os.Stat(filepath.Join(chartPath, file))
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func lintExitError(results []*charts.LintResult) error {
+	switch charts.HighestLintSeverity(results) {
+	case charts.LintSeverityError:
+		return &ExitCodeError{Code: exitCodeLintError, Err: errors.New("linting failed")}
+	case charts.LintSeverityWarning:
+		return &ExitCodeError{Code: exitCodeLintWarning, Err: errors.New("linting found warnings")}
+	default:
+		return nil
+	}
+}
+
+func (l *lintChartsCmd) writeToFile(out string) error {
+	f, err := charts.EnsureFileExists(l.outputDir, l.outputFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write([]byte(out))
+	return err
+}
@@ -22,10 +22,12 @@ Examples:
   $ helm charts list <path> <flags>
 
   flags:
-      --exclude-dirs        strings     List of (sub-)directories to exclude.
+      --exclude-dirs        strings     Gitignore-style patterns of (sub-)directories to exclude.
       --only-path           bool        Only output the chart path.
+  -o, --output              string      Output format. One of: table, json, yaml. (default "table")
       --output-dir          string      If given, results will be written to file in this directory.
       --output-filename     string      Filename to use for output. (default "results.txt")
+      --skip-library-charts bool        Do not list library charts.
 `
 
 type listChartsCmd struct {
@@ -34,10 +36,12 @@ type listChartsCmd struct {
 	excludeDirs []string
 	folder,
 	outputDir,
-	outputFilename string
+	outputFilename,
+	outputFormat string
 	useRelativePath,
 	writeOnlyChartPath,
-	writeOnlyChartName bool
+	writeOnlyChartName,
+	skipLibraryCharts bool
 }
 
 func newListChartsCmd() *cobra.Command {
@@ -87,6 +91,15 @@ func newListChartsCmd() *cobra.Command {
 				l.outputFilename = outputFileName
 			}
 
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat); err != nil {
+				return err
+			}
+			l.outputFormat = outputFormat
+
 			useRelativePath, err := cmd.Flags().GetBool(flagUseRelativePath)
 			if err != nil {
 				return err
@@ -105,11 +118,18 @@ func newListChartsCmd() *cobra.Command {
 			}
 			l.writeOnlyChartName = writeOnlyName
 
+			skipLibraryCharts, err := cmd.Flags().GetBool(flagSkipLibraryCharts)
+			if err != nil {
+				return err
+			}
+			l.skipLibraryCharts = skipLibraryCharts
+
 			return l.list()
 		},
 	}
 
 	addCommonFlags(cmd)
+	cmd.Flags().BoolP(flagSkipLibraryCharts, "", false, "Do not list library charts.")
 
 	return cmd
 }
@@ -120,15 +140,27 @@ func (l *listChartsCmd) list() error {
 		return err
 	}
 
+	if l.skipLibraryCharts {
+		results = charts.FilterLibraryCharts(results)
+	}
+
 	if len(results) == 0 {
 		return errors.New("not a single chart was found")
 	}
 
-	table := FormatTableOutput(results, "The following charts were found:", l.writeOnlyChartPath, l.writeOnlyChartName)
-	fmt.Println(table)
+	var out string
+	if isStructuredOutput(l.outputFormat) {
+		out, err = formatStructuredOutput(l.outputFormat, listOutput{Charts: newChartsOutput(results)})
+		if err != nil {
+			return err
+		}
+	} else {
+		out = FormatTableOutput(results, "The following charts were found:", l.writeOnlyChartPath, l.writeOnlyChartName)
+	}
+	fmt.Println(out)
 
 	if l.outputDir != "" {
-		return l.writeToFile(table)
+		return l.writeToFile(out)
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+// outputFormatMatrix is only supported by list-changed.
+const outputFormatMatrix = "matrix"
+
+// envGitHubOutput names the file in which GitHub Actions collects the outputs of a step.
+const envGitHubOutput = "GITHUB_OUTPUT"
+
+// matrixOutput is a job matrix as used by GitHub Actions.
+type matrixOutput struct {
+	Include []matrixEntry `json:"include"`
+}
+
+type matrixEntry struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// formatMatrixOutput returns the charts as compact JSON matrix in a single line.
+func formatMatrixOutput(results []*charts.HelmChart) (string, error) {
+	m := matrixOutput{Include: make([]matrixEntry, 0, len(results))}
+	for _, c := range newChartsOutput(results) {
+		m.Include = append(m.Include, matrixEntry{
+			Name:    c.Name,
+			Path:    c.Path,
+			Version: c.Version,
+		})
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// writeGitHubOutput appends the matrix and whether any chart was changed to the file named by $GITHUB_OUTPUT:
+//
+//	matrix={"include":[...]}
+//	changed=true
+func writeGitHubOutput(results []*charts.HelmChart) error {
+	fileName := os.Getenv(envGitHubOutput)
+	if fileName == "" {
+		return fmt.Errorf("$%s is not set", envGitHubOutput)
+	}
+
+	matrix, err := formatMatrixOutput(results)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "matrix=%s\nchanged=%s\n", matrix, strconv.FormatBool(len(results) > 0))
+	return errors.Join(err, f.Close())
+}
@@ -0,0 +1,214 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+var orderChartsLongUsage = `
+Print the Helm charts in the given folder in the order they have to be packaged, i.e. local (file://) dependencies first.
+Charts are grouped into levels. All charts of a level can be processed in parallel.
+
+Examples:
+  $ helm charts order <path> <flags>
+
+  flags:
+    --changed 			bool			Only order the charts changed compared to remote/branch:commit and the charts depending on them.
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --only-name 		bool			Only print the name of the chart.
+    --only-path         bool     		Only output the chart path.
+    -o, --output 		string			Output format. One of: table, json, yaml. (default "table")
+    --output-dir 		string      	If given, results will be written to file in this directory.
+    --output-filename 	string			Filename to use for output. (default "results.txt")
+
+  The flags --remote, --branch, --commit, --base, --head, --diff-mode, --uncommitted, --no-fetch and --git-backend are used with --changed.
+`
+
+type orderChartsCmd struct {
+	gitFlags
+
+	folder,
+	outputDir,
+	outputFilename,
+	outputFormat string
+	excludeDirs []string
+	isUseRelativePath,
+	writeOnlyChartPath,
+	writeOnlyChartName,
+	onlyChanged bool
+}
+
+func newOrderChartsCmd() *cobra.Command {
+	o := &orderChartsCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "order",
+		Long:         orderChartsLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			folder, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			o.folder = folder
+
+			excludeDirs, err := cmd.Flags().GetStringSlice(flagExcludeDirs)
+			if err != nil {
+				return err
+			}
+			o.excludeDirs = excludeDirs
+
+			outputDir, err := cmd.Flags().GetString(flagOutputDir)
+			if err != nil {
+				return err
+			}
+			o.outputDir = outputDir
+
+			outputFileName, err := cmd.Flags().GetString(flagOutputFileName)
+			if err != nil {
+				return err
+			}
+			o.outputFilename = outputFileName
+
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat); err != nil {
+				return err
+			}
+			o.outputFormat = outputFormat
+
+			useRelativePath, err := cmd.Flags().GetBool(flagUseRelativePath)
+			if err != nil {
+				return err
+			}
+			o.isUseRelativePath = useRelativePath
+
+			writeOnlyPath, err := cmd.Flags().GetBool(flagWriteOnlyPath)
+			if err != nil {
+				return err
+			}
+			o.writeOnlyChartPath = writeOnlyPath
+
+			writeOnlyName, err := cmd.Flags().GetBool(flagWriteOnlyName)
+			if err != nil {
+				return err
+			}
+			o.writeOnlyChartName = writeOnlyName
+
+			return o.order(cmd.Context())
+		},
+	}
+
+	addCommonFlags(cmd)
+	cmd.Flags().BoolVarP(&o.onlyChanged, "changed", "", false, "Only order the charts changed compared to remote/branch:commit and the charts depending on them.")
+	o.gitFlags.addFlags(cmd)
+
+	return cmd
+}
+
+func (o *orderChartsCmd) order(ctx context.Context) error {
+	var (
+		selected        []*charts.HelmChart
+		changedWarnings []charts.Warning
+	)
+	if o.onlyChanged {
+		changed, err := charts.ListChangedCharts(ctx, o.folder, charts.ChangedOptions{
+			ListOptions:       charts.ListOptions{ExcludeDirs: o.excludeDirs},
+			GitOptions:        o.gitOptions(),
+			Remote:            o.remote,
+			Branch:            o.branch,
+			Commit:            o.commit,
+			IncludeDependents: true,
+		})
+		if err != nil {
+			return err
+		}
+		changedWarnings = changed.Warnings
+		selected = changed.Charts
+	}
+
+	levels, warnings, err := charts.OrderHelmChartsInFolder(ctx, o.folder, selected, o.excludeDirs, o.isUseRelativePath)
+	if err != nil {
+		return err
+	}
+	printWarnings(changedWarnings, warnings)
+
+	var out string
+	switch {
+	case isStructuredOutput(o.outputFormat):
+		res := orderOutput{Levels: make([][]chartOutput, 0, len(levels))}
+		for _, l := range levels {
+			res.Levels = append(res.Levels, newChartsOutput(l))
+		}
+		out, err = formatStructuredOutput(o.outputFormat, res)
+		if err != nil {
+			return err
+		}
+	case len(levels) == 0:
+		fmt.Println("No charts to order.")
+		return nil
+	default:
+		out = o.formatTableOutput(levels)
+	}
+	fmt.Println(out)
+
+	if o.outputDir != "" {
+		return o.writeToFile(out)
+	}
+	return nil
+}
+
+type orderOutput struct {
+	Levels [][]chartOutput `json:"levels"`
+}
+
+func (o *orderChartsCmd) formatTableOutput(levels [][]*charts.HelmChart) string {
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	if !o.writeOnlyChartPath && !o.writeOnlyChartName {
+		table.AddRow("The charts have to be processed in the following order:")
+		table.AddRow("LEVEL", "NAME", "VERSION", "PATH")
+	}
+
+	for i, l := range levels {
+		for _, r := range l {
+			switch {
+			case o.writeOnlyChartPath:
+				table.AddRow(r.Path)
+			case o.writeOnlyChartName:
+				table.AddRow(r.Name)
+			default:
+				table.AddRow(i, r.Name, r.Version, r.Path)
+			}
+		}
+	}
+	return table.String()
+}
+
+func (o *orderChartsCmd) writeToFile(out string) error {
+	f, err := charts.EnsureFileExists(o.outputDir, o.outputFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write([]byte(out))
+	return err
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+const (
+	outputFormatTable = "table"
+	outputFormatJSON  = "json"
+	outputFormatYAML  = "yaml"
+)
+
+var outputFormats = []string{outputFormatTable, outputFormatJSON, outputFormatYAML}
+
+// chartOutput is the schema of a single chart in structured output.
+type chartOutput struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Path       string `json:"path"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Via        string `json:"via,omitempty"`
+	// Files is only set for changed charts if requested.
+	Files []changedFileOutput `json:"files,omitempty"`
+}
+
+type changedFileOutput struct {
+	Status  string `json:"status"`
+	Path    string `json:"path"`
+	OldPath string `json:"oldPath,omitempty"`
+}
+
+type listOutput struct {
+	Charts []chartOutput `json:"charts"`
+}
+
+// comparisonOutput describes the compared revisions in structured output.
+type comparisonOutput struct {
+	Remote string `json:"remote"`
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+	// Base and Head are only set if given instead of remote/branch and commit.
+	Base        string `json:"base,omitempty"`
+	Head        string `json:"head,omitempty"`
+	DiffMode    string `json:"diffMode"`
+	Uncommitted string `json:"uncommitted"`
+	// BaseCommit and HeadCommit are the hashes of the actually compared commits.
+	BaseCommit string `json:"baseCommit"`
+	HeadCommit string `json:"headCommit"`
+}
+
+type changedOutput struct {
+	comparisonOutput
+	Charts  []chartOutput        `json:"charts"`
+	Removed []removedChartOutput `json:"removed,omitempty"`
+}
+
+type duplicatesOutput struct {
+	Groups []duplicateGroupOutput `json:"groups"`
+}
+
+func newChartsOutput(results []*charts.HelmChart) []chartOutput {
+	res := make([]chartOutput, 0, len(results))
+	for _, r := range results {
+		c := chartOutput{
+			Name:       r.Name,
+			Path:       r.Path,
+			APIVersion: r.APIVersion,
+			Type:       r.Type,
+			Reason:     string(r.Reason),
+			Via:        r.Via,
+		}
+		if r.Version != nil {
+			c.Version = r.Version.String()
+		}
+		res = append(res, c)
+	}
+	return res
+}
+
+// validateOutputFormat checks the format against the formats supported by all commands and the given ones.
+func validateOutputFormat(format string, additionalFormats ...string) error {
+	formats := append(slices.Clone(outputFormats), additionalFormats...)
+	if !slices.Contains(formats, format) {
+		return fmt.Errorf("invalid output format %q, must be one of %v", format, formats)
+	}
+	return nil
+}
+
+// isStructuredOutput returns true if the results should be serialized instead of printed as table.
+func isStructuredOutput(format string) bool {
+	return format == outputFormatJSON || format == outputFormatYAML
+}
+
+func formatStructuredOutput(format string, v any) (string, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch format {
+	case outputFormatJSON:
+		b, err = json.MarshalIndent(v, "", "  ")
+	case outputFormatYAML:
+		b, err = yaml.Marshal(v)
+	default:
+		return "", fmt.Errorf("unsupported structured output format %q", format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// printWarnings writes the warnings to stderr to keep them out of structured output.
+// Warnings reported by several steps are only printed once.
+func printWarnings(warnings ...[]charts.Warning) {
+	printed := make(map[string]bool)
+	for _, ws := range warnings {
+		for _, w := range ws {
+			if !printed[w.String()] {
+				printed[w.String()] = true
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
+		}
+	}
+}
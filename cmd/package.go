@@ -0,0 +1,183 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+var packageChartsLongUsage = `
+Package the Helm charts in the given folder into versioned archives and generate a repository index.yaml.
+
+Examples:
+  $ helm charts package <path> <flags>
+
+  flags:
+    --base-url 			string			URL of the chart repository used in the index.yaml.
+    --changed 			bool			Only package the charts changed compared to remote/branch:commit.
+    --destination 		string			Directory the archives and the index.yaml are written to. (default ".")
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --merge 			string			Merge the given index.yaml into the generated one. (default "<destination>/index.yaml" if it exists)
+    -o, --output 		string			Output format. One of: table, json, yaml. (default "table")
+    --skip-library-charts	bool			Do not package library charts.
+
+  The flags --remote, --branch, --commit, --base, --head, --diff-mode, --uncommitted, --no-fetch and --git-backend are used with --changed.
+`
+
+type packageChartsCmd struct {
+	gitFlags
+
+	folder,
+	destination,
+	baseURL,
+	mergeIndex,
+	outputFormat string
+	excludeDirs []string
+	onlyChanged,
+	skipLibraryCharts bool
+}
+
+func newPackageChartsCmd() *cobra.Command {
+	p := &packageChartsCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "package",
+		Long:         packageChartsLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			folder, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			p.folder = folder
+
+			excludeDirs, err := cmd.Flags().GetStringSlice(flagExcludeDirs)
+			if err != nil {
+				return err
+			}
+			p.excludeDirs = excludeDirs
+
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat); err != nil {
+				return err
+			}
+			p.outputFormat = outputFormat
+
+			skipLibraryCharts, err := cmd.Flags().GetBool(flagSkipLibraryCharts)
+			if err != nil {
+				return err
+			}
+			p.skipLibraryCharts = skipLibraryCharts
+
+			return p.pack(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "Gitignore-style patterns of (sub-)directories to exclude.")
+	cmd.Flags().StringP(flagOutputFormat, "o", outputFormatTable, "Output format. One of: table, json, yaml.")
+	cmd.Flags().BoolP(flagSkipLibraryCharts, "", false, "Do not package library charts.")
+	cmd.Flags().StringVarP(&p.destination, "destination", "", ".", "Directory the archives and the index.yaml are written to.")
+	cmd.Flags().StringVarP(&p.baseURL, "base-url", "", "", "URL of the chart repository used in the index.yaml.")
+	cmd.Flags().StringVarP(&p.mergeIndex, "merge", "", "", "Merge the given index.yaml into the generated one. Defaults to the index.yaml in the destination if it exists.")
+	cmd.Flags().BoolVarP(&p.onlyChanged, "changed", "", false, "Only package the charts changed compared to remote/branch:commit.")
+	p.gitFlags.addFlags(cmd)
+
+	return cmd
+}
+
+func (p *packageChartsCmd) pack(ctx context.Context) error {
+	listOpts := charts.ListOptions{
+		ExcludeDirs:       p.excludeDirs,
+		SkipLibraryCharts: p.skipLibraryCharts,
+	}
+
+	var selected []*charts.HelmChart
+	if p.onlyChanged {
+		res, err := charts.ListChangedCharts(ctx, p.folder, charts.ChangedOptions{
+			ListOptions: listOpts,
+			GitOptions:  p.gitOptions(),
+			Remote:      p.remote,
+			Branch:      p.branch,
+			Commit:      p.commit,
+		})
+		if err != nil {
+			return err
+		}
+		printWarnings(res.Warnings)
+		selected = res.Charts
+	} else {
+		res, err := charts.ListCharts(ctx, p.folder, listOpts)
+		if err != nil {
+			return err
+		}
+		printWarnings(res.Warnings)
+		selected = res.Charts
+	}
+
+	results, err := charts.PackageHelmCharts(p.folder, selected, charts.PackageOptions{
+		Destination: p.destination,
+		BaseURL:     p.baseURL,
+		MergeIndex:  p.mergeIndex,
+	})
+	if err != nil {
+		return err
+	}
+
+	if isStructuredOutput(p.outputFormat) {
+		res := packageOutput{Charts: make([]packagedChartOutput, 0, len(results))}
+		for _, r := range results {
+			res.Charts = append(res.Charts, packagedChartOutput{
+				chartOutput: newChartsOutput([]*charts.HelmChart{r.Chart})[0],
+				Archive:     r.Archive,
+				Digest:      r.Digest,
+			})
+		}
+		out, err := formatStructuredOutput(p.outputFormat, res)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No charts to package.")
+		return nil
+	}
+
+	table := uitable.New()
+	table.MaxColWidth = 200
+	table.AddRow("The following charts were packaged:")
+	table.AddRow("NAME", "VERSION", "ARCHIVE")
+	for _, r := range results {
+		table.AddRow(r.Chart.Name, r.Chart.Version, r.Archive)
+	}
+	fmt.Println(table)
+	return nil
+}
+
+type packageOutput struct {
+	Charts []packagedChartOutput `json:"charts"`
+}
+
+type packagedChartOutput struct {
+	chartOutput
+	Archive string `json:"archive"`
+	Digest  string `json:"digest"`
+}
@@ -3,15 +3,25 @@
 
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
 
 const (
-	flagExcludeDirs     = "exclude-dirs"
-	flagOutputDir       = "output-dir"
-	flagOutputFileName  = "output-filename"
-	flagWriteOnlyPath   = "only-path"
-	flagWriteOnlyName   = "only-name"
-	flagUseRelativePath = "relative-path"
+	flagExcludeDirs       = "exclude-dirs"
+	flagOutputDir         = "output-dir"
+	flagOutputFileName    = "output-filename"
+	flagOutputFormat      = "output"
+	flagWriteOnlyPath     = "only-path"
+	flagWriteOnlyName     = "only-name"
+	flagUseRelativePath   = "relative-path"
+	flagSkipLibraryCharts = "skip-library-charts"
+	flagCache             = "cache"
+	flagClearCache        = "clear-cache"
 )
 
 var rootCmdLongUsage = `
@@ -21,6 +31,17 @@ Examples:
   $ helm charts list 		 <path> <flags>		- List Helm charts in the given directory.
   $ helm charts list-changed <path> <flags> 	- Identify and list Helm charts that were changed compared to another commit.
 	$ helm charts find-duplicates <path> <flags> - Find duplicate Helm charts in the given directory.
+  $ helm charts check-version-bump <path> <flags> - Check that the version of all changed Helm charts was increased.
+  $ helm charts graph <path> <flags> - Export the dependency graph of the Helm charts in the given directory.
+  $ helm charts order <path> <flags> - Print the Helm charts in the given directory in dependency order.
+  $ helm charts lint <path> <flags> - Lint the Helm charts in the given directory.
+  $ helm charts package <path> <flags> - Package the Helm charts in the given directory and generate a repository index.
+  $ helm charts config view <path> <flags> - Print the effective settings after applying the configuration file.
+
+Defaults for all flags can be set in a .helm-charts.yaml in the given directory or the root of the git repository.
+
+With --cache, the parsed metadata of charts is cached in $HELM_HOME/cache/helm-charts and reused as long as their
+Chart.yaml and requirements.yaml are not modified. Use --clear-cache to remove the cache.
 `
 
 func New() *cobra.Command {
@@ -28,22 +49,170 @@ func New() *cobra.Command {
 		Use:       "charts",
 		Long:      rootCmdLongUsage,
 		ValidArgs: []string{"chartpath"},
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Only the commands working on charts are configurable.
+			if cmd.Parent() != cmd.Root() {
+				return nil
+			}
+			cfg, err := loadConfig(cmd, args)
+			if err != nil {
+				return err
+			}
+			cmd.SetContext(withConfig(cmd.Context(), cfg))
+			if _, err = applyConfig(cmd, cfg); err != nil {
+				return err
+			}
+			return configureDiscoveryCache(cmd)
+		},
 	}
+	cmd.PersistentFlags().StringP(flagConfig, "", "", "Path of the configuration file. Defaults to .helm-charts.yaml in the given directory or the root of the git repository.")
+	cmd.PersistentFlags().BoolP(flagCache, "", false, "Cache the parsed chart metadata in $HELM_HOME/cache/helm-charts.")
+	cmd.PersistentFlags().BoolP(flagClearCache, "", false, "Remove the cache of parsed chart metadata before running the command.")
 
 	cmd.AddCommand(
 		newListChartsCmd(),
 		newChangedChartsCmd(),
 		newFindDuplicatesChartsCmd(),
+		newCheckVersionBumpCmd(),
+		newGraphCmd(),
+		newOrderChartsCmd(),
+		newLintChartsCmd(),
+		newPackageChartsCmd(),
+		newConfigCmd(),
 	)
 
 	return cmd
 }
 
+// configureDiscoveryCache enables the cache of parsed chart metadata if requested by the flags.
+func configureDiscoveryCache(cmd *cobra.Command) error {
+	useCache, err := cmd.Flags().GetBool(flagCache)
+	if err != nil {
+		return err
+	}
+	clearCache, err := cmd.Flags().GetBool(flagClearCache)
+	if err != nil {
+		return err
+	}
+
+	dir := charts.DefaultDiscoveryCacheDir()
+	if clearCache {
+		if err := charts.ClearDiscoveryCache(dir); err != nil {
+			return err
+		}
+	}
+	if useCache {
+		charts.SetDiscoveryCacheDir(dir)
+	}
+	return nil
+}
+
+// ExitCodeError is returned by commands that need to exit with a specific code.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
 func addCommonFlags(cmd *cobra.Command) {
-	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "List of (sub-)directories to exclude.")
+	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "Gitignore-style patterns of (sub-)directories to exclude.")
 	cmd.Flags().StringP(flagOutputDir, "", "", "If given, results will be written to file in this directory.")
 	cmd.Flags().StringP(flagOutputFileName, "", "results.txt", "Filename to use for output.")
+	cmd.Flags().StringP(flagOutputFormat, "o", outputFormatTable, "Output format. One of: table, json, yaml.")
 	cmd.Flags().BoolP(flagWriteOnlyPath, "", false, "Only output the chart path.")
 	cmd.Flags().BoolP(flagUseRelativePath, "", false, "Return chart path' relative to the given directory.")
 	cmd.Flags().BoolP(flagWriteOnlyName, "", false, "Only print the name of the chart.")
 }
+
+// gitFlags are used by all commands comparing against a git revision.
+type gitFlags struct {
+	remote,
+	branch,
+	commit,
+	gitBackend,
+	base,
+	head,
+	diffMode,
+	uncommitted string
+	noFetch     bool
+	sharedFiles []charts.SharedFileMapping
+}
+
+func (g *gitFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&g.remote, "remote", "", "origin", "The name of the git remote used to identify changes.")
+	cmd.Flags().StringVarP(&g.branch, "branch", "", "master", "The name of the branch used to identify changes.")
+	cmd.Flags().StringVarP(&g.commit, "commit", "", "HEAD", "The commit used to identify changes.")
+	cmd.Flags().BoolVarP(&g.noFetch, "no-fetch", "", false, "Do not fetch the remote branch but compare against the refs that exist locally.")
+	cmd.Flags().StringVarP(&g.gitBackend, "git-backend", "", string(charts.GitBackendExec), "How to access the git repository. One of: exec (git binary), go (no git binary required).")
+	cmd.Flags().StringVarP(&g.base, "base", "", "", "The revision to compare against instead of remote/branch. Nothing is fetched.")
+	cmd.Flags().StringVarP(&g.head, "head", "", "", "The revision to compare instead of --commit.")
+	cmd.Flags().StringVarP(&g.diffMode, "diff-mode", "", string(charts.DiffModeThreeDot), "One of: three-dot (compare against the merge base), two-dot (compare against the base itself).")
+	cmd.Flags().StringVarP(&g.uncommitted, "uncommitted", "", string(charts.UncommittedNone), "Include uncommitted changes. One of: none, staged, all (staged, unstaged and untracked).")
+
+	// The mapping of shared files can only be given in the configuration file.
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if cfg := configFromContext(cmd.Context()); cfg != nil {
+			g.sharedFiles = cfg.SharedFiles
+		}
+		return nil
+	}
+}
+
+func (g *gitFlags) gitOptions() charts.GitOptions {
+	return charts.GitOptions{
+		Backend:     charts.GitBackend(g.gitBackend),
+		NoFetch:     g.noFetch,
+		Base:        g.base,
+		Head:        g.head,
+		DiffMode:    charts.DiffMode(g.diffMode),
+		Uncommitted: charts.UncommittedChanges(g.uncommitted),
+		SharedFiles: g.sharedFiles,
+	}
+}
+
+// comparisonOutput returns the compared revisions for structured output with the hashes of the compared commits.
+func (g *gitFlags) comparisonOutput(baseCommit, headCommit string) comparisonOutput {
+	return comparisonOutput{
+		Remote:      g.remote,
+		Branch:      g.branch,
+		Commit:      g.commit,
+		Base:        g.base,
+		Head:        g.head,
+		DiffMode:    g.diffMode,
+		Uncommitted: g.uncommitted,
+		BaseCommit:  baseCommit,
+		HeadCommit:  headCommit,
+	}
+}
+
+// comparison describes the compared revisions, e.g. "origin/master:HEAD" or "v1.0..HEAD including all uncommitted changes".
+func (g *gitFlags) comparison() string {
+	head := g.commit
+	if g.head != "" {
+		head = g.head
+	}
+
+	res := fmt.Sprintf("%s/%s:%s", g.remote, g.branch, head)
+	if g.base != "" {
+		sep := "..."
+		if g.diffMode == string(charts.DiffModeTwoDot) {
+			sep = ".."
+		}
+		res = g.base + sep + head
+	}
+
+	switch charts.UncommittedChanges(g.uncommitted) {
+	case charts.UncommittedStaged:
+		res += " including staged changes"
+	case charts.UncommittedAll:
+		res += " including all uncommitted changes"
+	}
+	return res
+}
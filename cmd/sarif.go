@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+// outputFormatSARIF is supported by the commands checking charts.
+const outputFormatSARIF = "sarif"
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifSrcRoot is the base of all artifact locations, which are relative to the root of the git repository.
+	sarifSrcRoot = "%SRCROOT%"
+
+	sarifLevelError   = "error"
+	sarifLevelWarning = "warning"
+	sarifLevelNote    = "note"
+)
+
+// sarifRule is a kind of finding.
+type sarifRule struct {
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	ShortDescription sarifMessage     `json:"shortDescription"`
+	DefaultConfig    sarifRuleDefault `json:"defaultConfiguration"`
+}
+
+type sarifRuleDefault struct {
+	Level string `json:"level"`
+}
+
+func newSARIFRule(id, name, description, level string) sarifRule {
+	return sarifRule{
+		ID:               id,
+		Name:             name,
+		ShortDescription: sarifMessage{Text: description},
+		DefaultConfig:    sarifRuleDefault{Level: level},
+	}
+}
+
+var (
+	sarifRuleDuplicateName = newSARIFRule("HC001", "DuplicateChartName",
+		"Multiple charts have the same name.", sarifLevelError)
+	sarifRuleSimilarContent = newSARIFRule("HC002", "SimilarChartContent",
+		"Charts have identical or similar templates and values files.", sarifLevelWarning)
+	sarifRuleVersionNotBumped = newSARIFRule("HC003", "VersionNotBumped",
+		"The chart was changed but its version was not increased.", sarifLevelError)
+	sarifRuleInvalidChartMetadata = newSARIFRule("HC004", "InvalidChartMetadata",
+		"The Chart.yaml is invalid.", sarifLevelError)
+	sarifRuleLintError = newSARIFRule("HC005", "LintError",
+		"Helm lint reported an error.", sarifLevelError)
+	sarifRuleLintWarning = newSARIFRule("HC006", "LintWarning",
+		"Helm lint reported a warning.", sarifLevelWarning)
+	sarifRuleLintInfo = newSARIFRule("HC007", "LintInfo",
+		"Helm lint reported a recommendation.", sarifLevelNote)
+)
+
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []*sarifResult              `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string             `json:"ruleId"`
+	RuleIndex        int                `json:"ruleIndex"`
+	Level            string             `json:"level"`
+	Message          sarifMessage       `json:"message"`
+	Locations        []sarifLocation    `json:"locations"`
+	RelatedLocations []sarifLocation    `json:"relatedLocations,omitempty"`
+	Suppressions     []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// sarifBuilder collects the findings of a command. Only the rules of reported findings are included.
+type sarifBuilder struct {
+	// folder is the given folder, which relative chart paths are relative to.
+	folder string
+	// root is the directory artifact locations are relative to.
+	root      string
+	rules     []sarifRule
+	ruleIndex map[string]int
+	results   []*sarifResult
+}
+
+func newSARIFBuilder(folder string) *sarifBuilder {
+	root := charts.FindRepositoryRoot(folder)
+	if root == "" {
+		root = folder
+	}
+	return &sarifBuilder{
+		folder:    folder,
+		root:      root,
+		ruleIndex: make(map[string]int),
+	}
+}
+
+// add reports a finding with the default level of the rule, which can be adjusted by the caller.
+func (b *sarifBuilder) add(rule sarifRule, message string, location sarifLocation) *sarifResult {
+	idx, ok := b.ruleIndex[rule.ID]
+	if !ok {
+		idx = len(b.rules)
+		b.ruleIndex[rule.ID] = idx
+		b.rules = append(b.rules, rule)
+	}
+
+	r := &sarifResult{
+		RuleID:    rule.ID,
+		RuleIndex: idx,
+		Level:     rule.DefaultConfig.Level,
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{location},
+	}
+	b.results = append(b.results, r)
+	return r
+}
+
+// suppress marks a finding accepted by the allowlist.
+func (r *sarifResult) suppress(justification string) {
+	r.Suppressions = []sarifSuppression{{Kind: "external", Justification: justification}}
+}
+
+// chartFileLocation returns the location of a file in the chart. If key is given, the location points at the line of
+// this top-level key in the Chart.yaml.
+func (b *sarifBuilder) chartFileLocation(chartPath, file, key string) sarifLocation {
+	absChartPath := chartPath
+	if !filepath.IsAbs(absChartPath) {
+		absChartPath = filepath.Join(b.folder, chartPath)
+	}
+
+	uri := filepath.Join(absChartPath, file)
+	if relPath, err := filepath.Rel(b.root, uri); err == nil {
+		uri = relPath
+	}
+
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLoc{URI: filepath.ToSlash(uri), URIBaseID: sarifSrcRoot},
+		},
+	}
+	if key != "" {
+		if line := charts.FindChartMetadataLine(absChartPath, key); line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+		}
+	}
+	return loc
+}
+
+// yamlErrorLine matches the line number in errors of the YAML parser.
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// withErrorLine sets the region of the location to the line mentioned in the error message if any.
+func withErrorLine(loc sarifLocation, msg string) sarifLocation {
+	m := yamlErrorLine.FindStringSubmatch(msg)
+	if m == nil {
+		return loc
+	}
+	if line, err := strconv.Atoi(m[1]); err == nil && line > 0 {
+		loc.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+	}
+	return loc
+}
+
+func (b *sarifBuilder) format() (string, error) {
+	rootURI := url.URL{Scheme: "file", Path: filepath.ToSlash(b.root) + "/"}
+	report := sarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "helm-charts",
+				InformationURI: "https://github.com/sapcc/helm-charts-plugin",
+				Rules:          append(make([]sarifRule, 0, len(b.rules)), b.rules...),
+			}},
+			OriginalURIBaseIDs: map[string]sarifArtifactLoc{sarifSrcRoot: {URI: rootURI.String()}},
+			Results:            append(make([]*sarifResult, 0, len(b.results)), b.results...),
+		}},
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
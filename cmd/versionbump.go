@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"github.com/sapcc/helm-charts-plugin/pkg/charts"
+)
+
+var checkVersionBumpLongUsage = `
+Check that the version of every Helm chart changed compared to a given Git commit was increased.
+
+Examples:
+  $ helm charts check-version-bump <path> <flags>
+
+  flags:
+    --base 			string			The revision to compare against instead of remote/branch. Nothing is fetched.
+    --branch 			string			The name of the branch used to identify changes. (default "master")
+    --commit 			string          The commit used to identify changes. (default "HEAD")
+    --diff-mode 		string			One of: three-dot (compare against the merge base), two-dot (compare against the base itself). (default "three-dot")
+    --exclude-dirs 		strings   		Gitignore-style patterns of (sub-)directories to exclude.
+    --git-backend 		string			How to access the git repository. One of: exec, go. (default "exec")
+    --head 			string			The revision to compare instead of --commit.
+    --no-fetch 			bool			Do not fetch the remote branch but compare against the refs that exist locally.
+    -o, --output 		string			Output format. One of: table, json, yaml, junit, sarif. (default "table")
+    --output-dir 		string      	If given, results will be written to file in this directory.
+    --output-filename 	string			Filename to use for output. (default "results.txt")
+    --remote 			string          The name of the git remote used to identify changes. (default "origin)
+    --uncommitted 		string			Include uncommitted changes. One of: none, staged, all (staged, unstaged and untracked). (default "none")
+`
+
+type checkVersionBumpCmd struct {
+	gitFlags
+
+	directory         string
+	excludeDirs       []string
+	outputDir         string
+	outputFilename    string
+	outputFormat      string
+	isUseRelativePath bool
+}
+
+func newCheckVersionBumpCmd() *cobra.Command {
+	c := &checkVersionBumpCmd{}
+
+	cmd := &cobra.Command{
+		Use:          "check-version-bump",
+		Long:         checkVersionBumpLongUsage,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			d, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			c.directory = d
+
+			excludeDirs, err := cmd.Flags().GetStringSlice(flagExcludeDirs)
+			if err != nil {
+				return err
+			}
+			c.excludeDirs = excludeDirs
+
+			outputDir, err := cmd.Flags().GetString(flagOutputDir)
+			if err != nil {
+				return err
+			}
+			c.outputDir = outputDir
+
+			outputFileName, err := cmd.Flags().GetString(flagOutputFileName)
+			if err != nil {
+				return err
+			}
+			c.outputFilename = outputFileName
+
+			outputFormat, err := cmd.Flags().GetString(flagOutputFormat)
+			if err != nil {
+				return err
+			}
+			if err := validateOutputFormat(outputFormat, outputFormatJUnit, outputFormatSARIF); err != nil {
+				return err
+			}
+			c.outputFormat = outputFormat
+
+			useRelativePath, err := cmd.Flags().GetBool(flagUseRelativePath)
+			if err != nil {
+				return err
+			}
+			c.isUseRelativePath = useRelativePath
+
+			return c.checkVersionBump(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringSliceP(flagExcludeDirs, "", []string{}, "Gitignore-style patterns of (sub-)directories to exclude.")
+	cmd.Flags().StringP(flagOutputDir, "", "", "If given, results will be written to file in this directory.")
+	cmd.Flags().StringP(flagOutputFileName, "", "results.txt", "Filename to use for output.")
+	cmd.Flags().StringP(flagOutputFormat, "o", outputFormatTable, "Output format. One of: table, json, yaml, junit, sarif.")
+	cmd.Flags().BoolP(flagUseRelativePath, "", false, "Return chart path' relative to the given directory.")
+	c.gitFlags.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *checkVersionBumpCmd) checkVersionBump(ctx context.Context) error {
+	res, err := charts.CheckVersionBumps(ctx, c.directory, charts.ChangedOptions{
+		ListOptions: charts.ListOptions{
+			ExcludeDirs:     c.excludeDirs,
+			UseRelativePath: c.isUseRelativePath,
+		},
+		GitOptions: c.gitOptions(),
+		Remote:     c.remote,
+		Branch:     c.branch,
+		Commit:     c.commit,
+	})
+	if err != nil {
+		return err
+	}
+	printWarnings(res.Warnings)
+	results := res.Bumps
+
+	var (
+		out       string
+		notBumped []string
+	)
+	for _, r := range results {
+		if r.Status == charts.VersionNotBumped {
+			notBumped = append(notBumped, r.Chart.Name)
+		}
+	}
+
+	switch {
+	case c.outputFormat == outputFormatJUnit:
+		out, err = formatJUnitOutput("check-version-bump", newVersionBumpJUnitTestCases(results))
+		if err != nil {
+			return err
+		}
+	case c.outputFormat == outputFormatSARIF:
+		out, err = c.formatVersionBumpSARIFOutput(results)
+		if err != nil {
+			return err
+		}
+	case isStructuredOutput(c.outputFormat):
+		out, err = formatStructuredOutput(c.outputFormat, c.newVersionBumpOutput(res))
+		if err != nil {
+			return err
+		}
+	case len(results) == 0:
+		fmt.Println("Nothing was changed.")
+		return nil
+	default:
+		out = c.formatTableOutput(results)
+	}
+	fmt.Println(out)
+
+	if c.outputDir != "" {
+		if err := c.writeToFile(out); err != nil {
+			return err
+		}
+	}
+
+	if len(notBumped) > 0 {
+		return fmt.Errorf("the version of the following changed charts was not increased: %s", strings.Join(notBumped, ", "))
+	}
+	return nil
+}
+
+func (c *checkVersionBumpCmd) formatTableOutput(results []*charts.VersionBump) string {
+	table := uitable.New()
+	table.MaxColWidth = 200
+
+	table.AddRow(fmt.Sprintf("Compared to %s following charts were changed:", c.comparison()))
+	table.AddRow("NAME", "BASE VERSION", "VERSION", "PATH", "STATUS")
+	var newCharts []*charts.VersionBump
+	for _, r := range results {
+		if r.Status == charts.VersionNewChart {
+			newCharts = append(newCharts, r)
+			continue
+		}
+		table.AddRow(r.Chart.Name, r.BaseVersion, r.Chart.Version, r.Chart.Path, r.Status)
+	}
+
+	if len(newCharts) > 0 {
+		table.AddRow("")
+		table.AddRow("The following charts are new:")
+		table.AddRow("NAME", "VERSION", "PATH")
+		for _, r := range newCharts {
+			table.AddRow(r.Chart.Name, r.Chart.Version, r.Chart.Path)
+		}
+	}
+	return table.String()
+}
+
+type versionBumpOutput struct {
+	comparisonOutput
+	Charts    []versionBumpOutputRow `json:"charts"`
+	NewCharts []chartOutput          `json:"newCharts"`
+}
+
+type versionBumpOutputRow struct {
+	chartOutput
+	BaseVersion string `json:"baseVersion"`
+	Status      string `json:"status"`
+}
+
+func (c *checkVersionBumpCmd) newVersionBumpOutput(result *charts.VersionBumpResult) versionBumpOutput {
+	res := versionBumpOutput{
+		comparisonOutput: c.comparisonOutput(result.Base, result.Head),
+		Charts:           make([]versionBumpOutputRow, 0),
+		NewCharts:        make([]chartOutput, 0),
+	}
+
+	for _, r := range result.Bumps {
+		chart := newChartsOutput([]*charts.HelmChart{r.Chart})[0]
+		if r.Status == charts.VersionNewChart {
+			res.NewCharts = append(res.NewCharts, chart)
+			continue
+		}
+		res.Charts = append(res.Charts, versionBumpOutputRow{
+			chartOutput: chart,
+			BaseVersion: r.BaseVersion.String(),
+			Status:      string(r.Status),
+		})
+	}
+	return res
+}
+
+// newVersionBumpJUnitTestCases returns a test case per changed chart, which fails if the version was not increased.
+func newVersionBumpJUnitTestCases(results []*charts.VersionBump) []junitTestCase {
+	cases := make([]junitTestCase, 0, len(results))
+	for _, r := range results {
+		tc := newJUnitTestCase("check-version-bump", r.Chart)
+		switch r.Status {
+		case charts.VersionNotBumped:
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("version %s was not increased compared to %s", r.Chart.Version, r.BaseVersion),
+				Type:    string(r.Status),
+				Text:    filepath.Join(r.Chart.Path, "Chart.yaml"),
+			}
+		case charts.VersionNewChart:
+			tc.SystemOut = newJUnitText(fmt.Sprintf("new chart with version %s", r.Chart.Version))
+		default:
+			tc.SystemOut = newJUnitText(fmt.Sprintf("version increased from %s to %s", r.BaseVersion, r.Chart.Version))
+		}
+		cases = append(cases, tc)
+	}
+	return cases
+}
+
+// formatVersionBumpSARIFOutput reports the charts whose version was not increased at the version in their Chart.yaml.
+func (c *checkVersionBumpCmd) formatVersionBumpSARIFOutput(results []*charts.VersionBump) (string, error) {
+	b := newSARIFBuilder(c.directory)
+	for _, r := range results {
+		if r.Status != charts.VersionNotBumped {
+			continue
+		}
+		b.add(sarifRuleVersionNotBumped,
+			fmt.Sprintf("Chart %s was changed compared to %s but its version %s was not increased.", r.Chart.Name, c.comparison(), r.Chart.Version),
+			b.chartFileLocation(r.Chart.Path, "Chart.yaml", "version"))
+	}
+	return b.format()
+}
+
+func (c *checkVersionBumpCmd) writeToFile(out string) error {
+	f, err := charts.EnsureFileExists(c.outputDir, c.outputFilename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write([]byte(out))
+	return err
+}
@@ -4,13 +4,24 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
 
 	"github.com/sapcc/helm-charts-plugin/cmd"
 )
 
 func main() {
-	if err := cmd.New().Execute(); err != nil {
+	// Cancel running git commands on Ctrl+C.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := cmd.New().ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		var exitErr *cmd.ExitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
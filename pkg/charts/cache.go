@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// discoveryCacheVersion is increased whenever the format of the cache changes. Caches of other versions are discarded.
+const discoveryCacheVersion = 1
+
+// racyModTime is the duration after a modification in which the modification time is not trusted to detect
+// another modification, e.g. on file systems with a coarse timestamp resolution.
+const racyModTime = 2 * time.Second
+
+var (
+	discoveryCacheDirMu sync.RWMutex
+	discoveryCacheDir   string
+)
+
+// DefaultDiscoveryCacheDir returns the directory of the discovery cache below the helm home.
+func DefaultDiscoveryCacheDir() string {
+	return GetHelmHome().Path("cache", "helm-charts")
+}
+
+// SetDiscoveryCacheDir enables caching the parsed metadata of charts in the given directory.
+// Charts whose Chart.yaml and requirements.yaml were not modified since they were cached are not parsed again.
+// The cache is disabled by default and if the directory is empty.
+func SetDiscoveryCacheDir(dir string) {
+	discoveryCacheDirMu.Lock()
+	defer discoveryCacheDirMu.Unlock()
+	discoveryCacheDir = dir
+}
+
+// ClearDiscoveryCache removes all cached chart metadata from the given directory.
+func ClearDiscoveryCache(dir string) error {
+	return os.RemoveAll(dir)
+}
+
+// fileStamp identifies the state of a file without reading it.
+type fileStamp struct {
+	ModTime int64 `json:"modTime"`
+	Size    int64 `json:"size"`
+}
+
+// statFile returns the stamp of the given file or nil if it does not exist.
+func statFile(absPath string) (*fileStamp, error) {
+	info, err := os.Stat(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &fileStamp{ModTime: info.ModTime().UnixNano(), Size: info.Size()}, nil
+}
+
+func (s *fileStamp) equal(o *fileStamp) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return *s == *o
+}
+
+func (s *fileStamp) isRacy(now time.Time) bool {
+	return s != nil && now.Sub(time.Unix(0, s.ModTime)) < racyModTime
+}
+
+// cachedChart is the metadata of a chart together with the stamps of the files it was parsed from.
+type cachedChart struct {
+	ChartFile    *fileStamp              `json:"chartFile"`
+	Requirements *fileStamp              `json:"requirements,omitempty"`
+	Name         string                  `json:"name"`
+	Version      string                  `json:"version"`
+	APIVersion   string                  `json:"apiVersion"`
+	Type         string                  `json:"type,omitempty"`
+	KubeVersion  string                  `json:"kubeVersion,omitempty"`
+	Dependencies []*chartutil.Dependency `json:"dependencies,omitempty"`
+}
+
+func (c *cachedChart) helmChart(absPath string) (*HelmChart, error) {
+	version, err := semver.NewVersion(c.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &HelmChart{
+		Name:         c.Name,
+		Version:      version,
+		Path:         absPath,
+		APIVersion:   c.APIVersion,
+		Type:         c.Type,
+		KubeVersion:  c.KubeVersion,
+		Dependencies: c.Dependencies,
+	}, nil
+}
+
+// discoveryCache holds the metadata of the charts below a single folder.
+// A nil cache is valid and loads all charts from disk.
+type discoveryCache struct {
+	path string
+	now  time.Time
+
+	mu      sync.Mutex
+	charts  map[string]*cachedChart
+	used    map[string]*cachedChart
+	isDirty bool
+}
+
+type discoveryCacheFile struct {
+	Version int                     `json:"version"`
+	Charts  map[string]*cachedChart `json:"charts"`
+}
+
+// openDiscoveryCache returns the cache of the given folder or nil if caching is disabled.
+// A missing, unreadable or outdated cache file results in an empty cache.
+func openDiscoveryCache(folder string) *discoveryCache {
+	discoveryCacheDirMu.RLock()
+	dir := discoveryCacheDir
+	discoveryCacheDirMu.RUnlock()
+	if dir == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(folder))
+	c := &discoveryCache{
+		path:   filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"),
+		now:    time.Now(),
+		charts: make(map[string]*cachedChart),
+		used:   make(map[string]*cachedChart),
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c
+	}
+	var f discoveryCacheFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Version != discoveryCacheVersion {
+		return c
+	}
+	if f.Charts != nil {
+		c.charts = f.Charts
+	}
+	return c
+}
+
+// loadChartMetadata returns the cached metadata of the chart if its files were not modified and parses it otherwise.
+func (c *discoveryCache) loadChartMetadata(absPath string) (*HelmChart, error) {
+	if c == nil {
+		return loadChartMetadata(absPath)
+	}
+
+	chartFile, err := statFile(filepath.Join(absPath, chartMetadataName))
+	if err != nil || chartFile == nil {
+		return loadChartMetadata(absPath)
+	}
+	requirements, err := statFile(filepath.Join(absPath, requirementsFileName))
+	if err != nil {
+		return loadChartMetadata(absPath)
+	}
+
+	c.mu.Lock()
+	cached, ok := c.charts[absPath]
+	c.mu.Unlock()
+	if ok && cached.ChartFile.equal(chartFile) && cached.Requirements.equal(requirements) {
+		if chart, err := cached.helmChart(absPath); err == nil {
+			c.use(absPath, cached, false)
+			return chart, nil
+		}
+	}
+
+	chart, err := loadChartMetadata(absPath)
+	if err != nil {
+		return nil, err
+	}
+	if !chartFile.isRacy(c.now) && !requirements.isRacy(c.now) {
+		c.use(absPath, &cachedChart{
+			ChartFile:    chartFile,
+			Requirements: requirements,
+			Name:         chart.Name,
+			Version:      chart.Version.Original(),
+			APIVersion:   chart.APIVersion,
+			Type:         chart.Type,
+			KubeVersion:  chart.KubeVersion,
+			Dependencies: chart.Dependencies,
+		}, true)
+	}
+	return chart, nil
+}
+
+func (c *discoveryCache) use(absPath string, cached *cachedChart, isNew bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.used[absPath] = cached
+	if isNew {
+		c.isDirty = true
+	}
+}
+
+// save writes the entries used since the cache was opened, so that removed charts are dropped.
+// The cache is only an optimization, so failing to write it is not an error.
+func (c *discoveryCache) save() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isDirty && len(c.used) == len(c.charts) {
+		return
+	}
+
+	data, err := json.Marshal(discoveryCacheFile{Version: discoveryCacheVersion, Charts: c.used})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return
+	}
+	// Write to a temporary file first, so that concurrent runs never read a partially written cache.
+	f, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name()) //nolint:errcheck // best effort, the cache is written again on the next run
+	}
+}
@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/ghodss/yaml"
+)
+
+// ConfigFileName is the name of the configuration file providing defaults for the flags of all commands.
+const ConfigFileName = ".helm-charts.yaml"
+
+// Config contains flag defaults read from the configuration file, e.g.
+//
+//	defaults:
+//	  branch: main
+//	  exclude-dirs: [test, "**/examples"]
+//	commands:
+//	  list-changed:
+//	    include-dependents: true
+//	sharedFiles:
+//	- paths: [global/values/*.yaml]
+//	  charts: ["openstack/*"]
+//
+// The keys are the names of the flags. Values of the commands section take precedence over the defaults.
+type Config struct {
+	// Path is the absolute path of the configuration file. Empty if no file was found.
+	Path     string                            `json:"-"`
+	Defaults map[string]interface{}            `json:"defaults,omitempty"`
+	Commands map[string]map[string]interface{} `json:"commands,omitempty"`
+	// SharedFiles are used by all commands comparing against a git revision.
+	SharedFiles []SharedFileMapping `json:"sharedFiles,omitempty"`
+}
+
+// LoadConfig reads the configuration file from the given folder or, if it does not exist there,
+// from the root of the git repository containing the folder. An empty configuration is returned if neither exists.
+func LoadConfig(folder string) (*Config, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	for dir := folder; ; dir = filepath.Dir(dir) {
+		fileName := filepath.Join(dir, ConfigFileName)
+		if _, err := os.Stat(fileName); err == nil {
+			return LoadConfigFile(fileName)
+		}
+
+		// Stop at the root of the repository.
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil || dir == filepath.Dir(dir) {
+			return &Config{}, nil
+		}
+	}
+}
+
+// LoadConfigFile reads the given configuration file.
+func LoadConfigFile(fileName string) (*Config, error) {
+	absPath, err := filepath.Abs(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", absPath, err)
+	}
+	cfg.Path = absPath
+	return cfg, nil
+}
+
+// Lookup returns the value configured for the flag of the given command.
+func (c *Config) Lookup(command, flag string) (interface{}, bool) {
+	if v, ok := c.Commands[command][flag]; ok {
+		return v, true
+	}
+	v, ok := c.Defaults[flag]
+	return v, ok
+}
+
+// Validate checks that the commands section only contains known commands and flags.
+// Unknown keys of the defaults section are allowed since not every command supports every flag.
+func (c *Config) Validate(flagsByCommand map[string][]string) error {
+	var errs []error
+	for _, command := range slices.Sorted(maps.Keys(c.Commands)) {
+		known, ok := flagsByCommand[command]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown command %q", c.Path, command))
+			continue
+		}
+		for _, flag := range slices.Sorted(maps.Keys(c.Commands[command])) {
+			if !slices.Contains(known, flag) {
+				errs = append(errs, fmt.Errorf("%s: unknown flag %q for command %q", c.Path, flag, command))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
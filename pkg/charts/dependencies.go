@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+const (
+	requirementsFileName  = "requirements.yaml"
+	localRepositoryPrefix = "file://"
+)
+
+// ChangeReason describes why a chart was reported as changed.
+type ChangeReason string
+
+const (
+	// ChangeReasonChanged is used for charts whose own directory contains changes.
+	ChangeReasonChanged ChangeReason = "changed"
+	// ChangeReasonDependency is used for charts that depend on a changed chart.
+	ChangeReasonDependency ChangeReason = "dependency"
+	// ChangeReasonSubchart is used for charts containing a changed subchart.
+	ChangeReasonSubchart ChangeReason = "subchart"
+	// ChangeReasonSharedFile is used for charts using a changed file outside of chart directories.
+	ChangeReasonSharedFile ChangeReason = "shared-file"
+)
+
+// IncludeDependentHelmCharts extends the given list of changed charts by all charts in the root directory
+// that depend on one of them via a local (file://) dependency. Dependents are added transitively.
+// Charts whose metadata cannot be loaded are reported as warnings.
+func IncludeDependentHelmCharts(ctx context.Context, rootDirectory string, changed []*HelmChart, excludeDirs []string, isUseRelativePath bool) ([]*HelmChart, []Warning, error) {
+	rootDirectory, err := filepath.Abs(rootDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allCharts, warnings, err := listHelmCharts(ctx, rootDirectory, excludeDirs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	graph := newDependencyGraph(allCharts)
+
+	res := make([]*HelmChart, 0, len(changed))
+	seen := make(map[string]bool, len(changed))
+	queue := make([]*HelmChart, 0, len(changed))
+	for _, c := range changed {
+		absPath := c.Path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(rootDirectory, absPath)
+		}
+		seen[absPath] = true
+		res = append(res, c)
+		if node, ok := graph.charts[absPath]; ok {
+			queue = append(queue, node)
+		}
+	}
+
+	for len(queue) > 0 {
+		dependency := queue[0]
+		queue = queue[1:]
+
+		for _, dependent := range graph.dependents[dependency.Path] {
+			if seen[dependent.Path] {
+				continue
+			}
+			seen[dependent.Path] = true
+			queue = append(queue, dependent)
+
+			c := *dependent
+			c.Reason = ChangeReasonDependency
+			c.Via = dependency.Name
+			if isUseRelativePath {
+				relPath, err := filepath.Rel(rootDirectory, c.Path)
+				if err != nil {
+					return nil, nil, err
+				}
+				c.Path = relPath
+			}
+			res = append(res, &c)
+		}
+	}
+
+	return sortChartsAlphabetically(res), warnings, nil
+}
+
+// dependencyGraph holds the local dependencies between the charts of a folder.
+type dependencyGraph struct {
+	// charts by absolute path.
+	charts map[string]*HelmChart
+	// dependencies maps the absolute path of a chart to the charts it depends on.
+	dependencies map[string][]*HelmChart
+	// dependents maps the absolute path of a chart to the charts depending on it.
+	dependents map[string][]*HelmChart
+}
+
+func newDependencyGraph(charts []*HelmChart) *dependencyGraph {
+	g := &dependencyGraph{
+		charts:       make(map[string]*HelmChart, len(charts)),
+		dependencies: make(map[string][]*HelmChart),
+		dependents:   make(map[string][]*HelmChart),
+	}
+	for _, c := range charts {
+		g.charts[c.Path] = c
+	}
+
+	for _, c := range charts {
+		for _, d := range c.Dependencies {
+			dep, ok := g.charts[resolveLocalDependency(c.Path, d)]
+			if !ok {
+				continue
+			}
+			g.dependencies[c.Path] = append(g.dependencies[c.Path], dep)
+			g.dependents[dep.Path] = append(g.dependents[dep.Path], c)
+		}
+	}
+
+	return g
+}
+
+// resolveLocalDependency returns the absolute path of a local dependency or an empty string for remote ones.
+func resolveLocalDependency(absPathChartFolder string, dep *chartutil.Dependency) string {
+	if !strings.HasPrefix(dep.Repository, localRepositoryPrefix) {
+		return ""
+	}
+
+	p := strings.TrimPrefix(dep.Repository, localRepositoryPrefix)
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(absPathChartFolder, p)
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// maxChartLoaders bounds the number of charts whose metadata is loaded concurrently.
+var maxChartLoaders = runtime.GOMAXPROCS(0)
+
+// listHelmCharts returns the charts in the given folder and a warning for every chart whose metadata could not be loaded.
+// The directory tree is walked once to find the charts, then their metadata is loaded concurrently.
+func listHelmCharts(ctx context.Context, folder string, excludeDirs []string, isUseRelativePath bool) ([]*HelmChart, []Warning, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, err := newExcludeRules(folder, excludeDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chartPaths, err := findChartDirectories(ctx, folder, rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache := openDiscoveryCache(folder)
+	loaded := loadChartsConcurrently(ctx, chartPaths, cache.loadChartMetadata)
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	cache.save()
+
+	var (
+		charts   []*HelmChart
+		warnings []Warning
+	)
+	for i, l := range loaded {
+		if l.err != nil {
+			warnings = append(warnings, newChartMetadataWarning(chartPaths[i], l.err))
+			continue
+		}
+
+		if isUseRelativePath {
+			relPath, err := filepath.Rel(folder, l.chart.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			l.chart.Path = relPath
+		}
+		charts = append(charts, l.chart)
+	}
+
+	return sortChartsAlphabetically(charts), warnings, nil
+}
+
+// findChartDirectories returns the directories containing a Chart.yaml in lexical order.
+// Excluded directories, version control metadata and the templates of charts are not descended into
+// since they never contain charts. Other subdirectories of charts are, since they can contain subcharts.
+func findChartDirectories(ctx context.Context, folder string, rules *excludeRules) ([]string, error) {
+	var res []string
+	isChart := make(map[string]struct{})
+	err := filepath.WalkDir(folder, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() {
+			if d.Name() != chartMetadataName {
+				return nil
+			}
+			dir := filepath.Dir(absPath)
+			if _, ok := isChart[dir]; !ok {
+				isChart[dir] = struct{}{}
+				res = append(res, dir)
+			}
+			return nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if rules.isExcluded(absPath) || isPrunedDirectory(absPath, isChart) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return res, err
+}
+
+// isPrunedDirectory returns true for directories that cannot contain charts.
+// Entries are walked in lexical order, so the Chart.yaml of a chart is seen before its templates and crds.
+func isPrunedDirectory(absPath string, isChart map[string]struct{}) bool {
+	switch filepath.Base(absPath) {
+	case ".git", ".hg", ".svn":
+		return true
+	case "templates", "crds":
+		_, ok := isChart[filepath.Dir(absPath)]
+		return ok
+	default:
+		return false
+	}
+}
+
+type chartLoadResult struct {
+	chart *HelmChart
+	err   error
+}
+
+// loadChartsConcurrently loads the metadata of the given charts with a bounded number of workers.
+// The results are in the order of the paths. Charts are not loaded anymore once the context is done.
+func loadChartsConcurrently(ctx context.Context, chartPaths []string, load func(absPath string) (*HelmChart, error)) []chartLoadResult {
+	res := make([]chartLoadResult, len(chartPaths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(maxChartLoaders, len(chartPaths)) {
+		wg.Go(func() {
+			for i := range jobs {
+				c, err := load(chartPaths[i])
+				res[i] = chartLoadResult{chart: c, err: err}
+			}
+		})
+	}
+
+loop:
+	for i := range chartPaths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return res
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// DuplicatesAllowlistFileName is the name of the file in the scanned root directory listing accepted duplicates.
+const DuplicatesAllowlistFileName = ".helm-charts-duplicates.yaml"
+
+// DuplicateGroup contains all charts sharing the same name.
+type DuplicateGroup struct {
+	Name   string
+	Charts []*HelmChart
+	// Accepted is true if the group is listed in the allowlist.
+	Accepted bool
+	// Reason is the reason given in the allowlist.
+	Reason string
+}
+
+// DuplicatesAllowlist lists intentional duplicates, e.g.
+//
+//	duplicates:
+//	- name: keystone
+//	  paths: [eu-de-1/keystone, eu-nl-1/keystone]
+//	  reason: regional variants
+//
+// Paths are relative to the scanned root directory. If no paths are given, all charts with the name are accepted.
+// Otherwise, a group is only accepted as long as all its charts are listed, so that additional copies are reported.
+type DuplicatesAllowlist struct {
+	Duplicates []AcceptedDuplicate `json:"duplicates"`
+}
+
+// AcceptedDuplicate is a single entry of the DuplicatesAllowlist.
+type AcceptedDuplicate struct {
+	Name   string   `json:"name,omitempty"`
+	Paths  []string `json:"paths,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// FindDuplicateChartGroupsInFolder finds charts with the same name at different paths in the given folder.
+// The groups are sorted by name, the charts of a group by path.
+func FindDuplicateChartGroupsInFolder(folder string, excludeDirs []string, isUseRelativePath bool) ([]*DuplicateGroup, error) {
+	foundCharts, err := ListHelmChartsInFolder(folder, excludeDirs, isUseRelativePath)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string][]*HelmChart)
+	for _, c := range foundCharts {
+		byName[c.Name] = append(byName[c.Name], c)
+	}
+
+	var res []*DuplicateGroup
+	for name, charts := range byName {
+		if len(charts) < 2 {
+			continue
+		}
+		res = append(res, &DuplicateGroup{
+			Name:   name,
+			Charts: sortChartsAlphabetically(charts),
+		})
+	}
+
+	slices.SortFunc(res, func(a, b *DuplicateGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res, nil
+}
+
+// LoadDuplicatesAllowlist reads the given allowlist file.
+func LoadDuplicatesAllowlist(fileName string) (*DuplicatesAllowlist, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := &DuplicatesAllowlist{}
+	if err := yaml.Unmarshal(data, allowlist); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", fileName, err)
+	}
+
+	for i, d := range allowlist.Duplicates {
+		if d.Name == "" && len(d.Paths) < 2 {
+			return nil, fmt.Errorf("%s: entry %d requires a name or at least two paths", fileName, i)
+		}
+		for j, p := range d.Paths {
+			allowlist.Duplicates[i].Paths[j] = filepath.Clean(p)
+		}
+	}
+	return allowlist, nil
+}
+
+// Apply marks the groups accepted by the allowlist. Chart paths are resolved relative to the root directory.
+func (a *DuplicatesAllowlist) Apply(rootDirectory string, groups []*DuplicateGroup) {
+	for _, g := range groups {
+		if d := a.find(rootDirectory, g.Name, g.Charts); d != nil {
+			g.Accepted = true
+			g.Reason = d.Reason
+		}
+	}
+}
+
+// IsAcceptedPair returns true if both charts are listed by the paths of the same entry.
+// This is used for charts with similar content which do not necessarily share the same name.
+func (a *DuplicatesAllowlist) IsAcceptedPair(rootDirectory string, charts [2]*HelmChart) bool {
+	return a.find(rootDirectory, "", charts[:]) != nil
+}
+
+func (a *DuplicatesAllowlist) find(rootDirectory, name string, charts []*HelmChart) *AcceptedDuplicate {
+	for i, d := range a.Duplicates {
+		switch {
+		case len(d.Paths) == 0:
+			// Entries without paths accept all charts with the name.
+			if name != "" && d.Name == name {
+				return &a.Duplicates[i]
+			}
+		case name == "" || d.Name == "" || d.Name == name:
+			if containsAllPaths(rootDirectory, d.Paths, charts) {
+				return &a.Duplicates[i]
+			}
+		}
+	}
+	return nil
+}
+
+func containsAllPaths(rootDirectory string, paths []string, charts []*HelmChart) bool {
+	for _, c := range charts {
+		relPath := c.Path
+		if filepath.IsAbs(relPath) {
+			var err error
+			relPath, err = filepath.Rel(rootDirectory, relPath)
+			if err != nil {
+				return false
+			}
+		}
+		if !slices.Contains(paths, filepath.Clean(relPath)) {
+			return false
+		}
+	}
+	return true
+}
@@ -5,7 +5,9 @@ package charts
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,37 +19,180 @@ var (
 	errGitNotInstalled = errors.New("git is not installed")
 	errNoGitRepository = errors.New("folder is not a git repository")
 	errNoRemote        = errors.New("no remote configured in git repository")
+	errFileNotFound    = errors.New("file does not exist in revision")
 )
 
+// worktreeRevision is passed to gitBackend.readFile to read the file from the work tree instead of a commit.
+const worktreeRevision = ""
+
+// GitBackend selects how the git repository is accessed.
+type GitBackend string
+
+const (
+	// GitBackendExec runs the git binary.
+	GitBackendExec GitBackend = "exec"
+	// GitBackendGo reads the repository directly without requiring git to be installed.
+	GitBackendGo GitBackend = "go"
+)
+
+// GitBackends lists all available git backends.
+var GitBackends = []GitBackend{GitBackendExec, GitBackendGo}
+
+// DiffMode selects against which revision the head is compared.
+type DiffMode string
+
+const (
+	// DiffModeThreeDot compares against the merge base of base and head like git diff base...head.
+	DiffModeThreeDot DiffMode = "three-dot"
+	// DiffModeTwoDot compares against the base itself like git diff base..head.
+	DiffModeTwoDot DiffMode = "two-dot"
+)
+
+// DiffModes lists all available diff modes.
+var DiffModes = []DiffMode{DiffModeThreeDot, DiffModeTwoDot}
+
+// UncommittedChanges selects which changes of the work tree are included in addition to the committed ones.
+type UncommittedChanges string
+
+const (
+	// UncommittedNone only compares commits.
+	UncommittedNone UncommittedChanges = "none"
+	// UncommittedStaged compares the index against the base like git diff --cached.
+	UncommittedStaged UncommittedChanges = "staged"
+	// UncommittedAll compares the work tree against the base including staged, unstaged and untracked files.
+	UncommittedAll UncommittedChanges = "all"
+)
+
+// UncommittedModes lists all available modes for uncommitted changes.
+var UncommittedModes = []UncommittedChanges{UncommittedNone, UncommittedStaged, UncommittedAll}
+
+// GitOptions configure how ListChangedHelmChartsInFolderWithGitOptions accesses the git repository.
+type GitOptions struct {
+	Backend GitBackend
+	// NoFetch compares against the refs that exist locally instead of fetching the remote branch first.
+	NoFetch bool
+	// Base is the revision compared against instead of remote/branch. Nothing is fetched if it is set.
+	Base string
+	// Head is the revision compared instead of the given commit.
+	Head string
+	// DiffMode defaults to DiffModeThreeDot.
+	DiffMode DiffMode
+	// Uncommitted includes changes of the work tree, which requires the head to be HEAD.
+	// Files at the head are read from the work tree then, also for staged changes.
+	Uncommitted UncommittedChanges
+	// SharedFiles map changed files outside of chart directories to the charts using them.
+	SharedFiles []SharedFileMapping
+}
+
+// FileChangeStatus is the kind of change of a file between two revisions.
+type FileChangeStatus string
+
+const (
+	FileAdded    FileChangeStatus = "added"
+	FileModified FileChangeStatus = "modified"
+	FileDeleted  FileChangeStatus = "deleted"
+	FileRenamed  FileChangeStatus = "renamed"
+)
+
+// ChangedFile is a file that differs between two revisions.
+type ChangedFile struct {
+	Status FileChangeStatus
+	// Path of the file at the target revision or, if it was deleted, at the base revision.
+	Path string
+	// OldPath is the path at the base revision of a renamed file.
+	OldPath string
+}
+
+// gitBackend is implemented by all ways to access a git repository.
+type gitBackend interface {
+	// fetch updates the remote-tracking ref of the given branch only.
+	fetch(ctx context.Context, branch string) error
+	getCommitHash(ctx context.Context, commit string) (string, error)
+	getMergeBase(ctx context.Context, commit1, commit2 string) (string, error)
+	// getChangedFiles returns the files below the directory that differ between the revisions with absolute paths.
+	// The commit is ignored if uncommitted changes are included.
+	getChangedFiles(ctx context.Context, base, commit string, uncommitted UncommittedChanges) ([]*ChangedFile, error)
+	// readFile returns the content of the file at the given absolute path in the given revision or the work tree
+	// (worktreeRevision) or errFileNotFound if it does not exist.
+	readFile(ctx context.Context, rev, absPath string) ([]byte, error)
+}
+
+// newGitBackend returns the given backend. The context is only used to open the repository, all operations of
+// the backend are cancelled when the context passed to them is done.
+func newGitBackend(ctx context.Context, backend GitBackend, directory, remote string) (gitBackend, error) {
+	switch backend {
+	case GitBackendExec, "":
+		g, err := newGit(ctx, directory, remote)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	case GitBackendGo:
+		g, err := newGoGit(directory, remote)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	default:
+		return nil, fmt.Errorf("unknown git backend %q", backend)
+	}
+}
+
+// git is the gitBackend running the git binary.
 type git struct {
 	remote    string
 	directory string
+	// workDir is the directory git runs in, which is the nearest existing one as the directory might have been removed.
+	workDir string
+	// root is the top-level directory of the work tree.
+	root string
 }
 
-func newGit(directory, remote string) (*git, error) {
+func newGit(ctx context.Context, directory, remote string) (*git, error) {
 	g := &git{
 		directory: directory,
+		workDir:   nearestExistingDirectory(directory),
 		remote:    remote,
 	}
 
-	err := g.testGitInstalled()
+	err := g.testGitInstalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.testGitRepository(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = g.testGitRepository()
+	// git reports paths relative to the top-level directory.
+	g.root, err = g.runGitCmd(ctx, "rev-parse", "--show-toplevel")
 	return g, err
 }
 
-func (g *git) testGitInstalled() error {
-	if _, err := g.runGitCmd("--version"); err != nil {
+func nearestExistingDirectory(directory string) string {
+	for dir := directory; ; dir = filepath.Dir(dir) {
+		if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			return directory
+		}
+	}
+}
+
+func (g *git) testGitInstalled(ctx context.Context) error {
+	if _, err := g.runGitCmd(ctx, "--version"); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		return errGitNotInstalled
 	}
 	return nil
 }
 
-func (g *git) testGitRepository() error {
-	stdout, err := g.runGitCmd("rev-parse", "--is-inside-work-tree")
+func (g *git) testGitRepository(ctx context.Context) error {
+	stdout, err := g.runGitCmd(ctx, "rev-parse", "--is-inside-work-tree")
 	if err != nil {
 		return err
 	}
@@ -64,67 +209,145 @@ func (g *git) testGitRepository() error {
 	return nil
 }
 
-func (g *git) fetch() error {
-	stdout, err := g.runGitCmd("remote", "get-url", g.remote)
+func (g *git) fetch(ctx context.Context, branch string) error {
+	stdout, err := g.runGitCmd(ctx, "remote", "get-url", g.remote)
 	if err != nil || stdout == "" {
 		return errNoRemote
 	}
 
-	_, err = g.runGitCmd("fetch")
+	_, err = g.runGitCmd(ctx, "fetch", g.remote, fetchRefSpec(g.remote, branch))
 	return err
 }
 
-func (g *git) getChangedDirs(remote, commit string) ([]string, error) {
-	stdOut, err := g.runGitCmd("diff", "--find-renames", "--name-only", remote, commit, "--", g.directory)
+// fetchRefSpec returns the refspec updating the remote-tracking ref of the given branch.
+func fetchRefSpec(remote, branch string) string {
+	return fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
+}
+
+func (g *git) getChangedFiles(ctx context.Context, base, commit string, uncommitted UncommittedChanges) ([]*ChangedFile, error) {
+	args := []string{"diff", "--find-renames", "--name-status"}
+	switch uncommitted {
+	case UncommittedStaged:
+		args = append(args, "--cached", base)
+	case UncommittedAll:
+		args = append(args, base)
+	default:
+		args = append(args, base, commit)
+	}
+
+	stdOut, err := g.runGitCmd(ctx, append(args, "--", g.directory)...)
 	if err != nil {
 		return nil, err
 	}
 
-	var changedDirs []string
+	var changedFiles []*ChangedFile
 	lines := strings.SplitSeq(stdOut, "\n")
 	for l := range lines {
-		if p := l; p != "" {
-			changedDirs = append(changedDirs, g.pathWithDirectory(p))
+		// <status>\t<path> or <status>\t<old path>\t<new path> for renames and copies.
+		fields := strings.Split(l, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+
+		f := &ChangedFile{
+			Status: parseFileChangeStatus(fields[0]),
+			Path:   g.absPath(fields[len(fields)-1]),
+		}
+		if f.Status == FileRenamed && len(fields) == 3 {
+			f.OldPath = g.absPath(fields[1])
+		}
+		changedFiles = append(changedFiles, f)
+	}
+
+	if uncommitted == UncommittedAll {
+		// git diff does not report untracked files.
+		stdOut, err := g.runGitCmd(ctx, "ls-files", "--others", "--exclude-standard", "--full-name", "--", g.directory)
+		if err != nil {
+			return nil, err
+		}
+		for l := range strings.SplitSeq(stdOut, "\n") {
+			if l != "" {
+				changedFiles = append(changedFiles, &ChangedFile{Status: FileAdded, Path: g.absPath(l)})
+			}
 		}
 	}
 
-	return changedDirs, nil
+	return changedFiles, nil
 }
 
-func (g *git) getCommitHash(commit string) (string, error) {
-	stdOut, err := g.runGitCmd("rev-parse", commit)
+// parseFileChangeStatus converts the status letter of git diff --name-status.
+// Copies are reported as added files, type changes and unmerged files as modified ones.
+func parseFileChangeStatus(status string) FileChangeStatus {
+	switch {
+	case strings.HasPrefix(status, "A"), strings.HasPrefix(status, "C"):
+		return FileAdded
+	case strings.HasPrefix(status, "D"):
+		return FileDeleted
+	case strings.HasPrefix(status, "R"):
+		return FileRenamed
+	default:
+		return FileModified
+	}
+}
+
+func (g *git) getCommitHash(ctx context.Context, commit string) (string, error) {
+	stdOut, err := g.runGitCmd(ctx, "rev-parse", commit)
 	return stdOut, err
 }
 
-func (g *git) getMergeBase(commit1, commit2 string) (string, error) {
-	stdOut, err := g.runGitCmd("merge-base", commit1, commit2)
+func (g *git) getMergeBase(ctx context.Context, commit1, commit2 string) (string, error) {
+	return g.runGitCmd(ctx, "merge-base", commit1, commit2)
+}
+
+func (g *git) readFile(ctx context.Context, rev, absPath string) ([]byte, error) {
+	if rev == worktreeRevision {
+		return readWorkTreeFile(absPath)
+	}
+
+	relPath, err := filepath.Rel(g.root, absPath)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	relPath = filepath.ToSlash(relPath)
 
-	if stdOut == commit2 {
-		stdOut = "HEAD"
+	// git show fails loudly for missing files, so check for existence first.
+	stdOut, err := g.runGitCmd(ctx, "ls-tree", "--full-tree", "--name-only", rev, "--", relPath)
+	if err != nil {
+		return nil, err
+	}
+	if stdOut == "" {
+		return nil, errFileNotFound
 	}
 
-	return stdOut, err
+	stdOut, err = g.runGitCmd(ctx, "show", fmt.Sprintf("%s:%s", rev, relPath))
+	return []byte(stdOut), err
 }
 
-func (g *git) runGitCmd(args ...string) (stdOutString string, err error) {
+func readWorkTreeFile(absPath string) ([]byte, error) {
+	data, err := os.ReadFile(absPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errFileNotFound
+	}
+	return data, err
+}
+
+func (g *git) runGitCmd(ctx context.Context, args ...string) (stdOutString string, err error) {
 	var stdout bytes.Buffer
 
-	cmd := exec.Command("git", append([]string{"-C", g.directory}, args...)...) //nolint:gosec // all arguments are used supplied
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.workDir}, args...)...) //nolint:gosec // all arguments are used supplied
 	cmd.Stdout = &stdout
 	cmd.Stderr = os.Stderr
 	err = cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
 
 	stdOutString = string(bytes.TrimSpace(stdout.Bytes()))
 
 	return stdOutString, err
 }
 
-func (g *git) pathWithDirectory(path string) string {
-	// Avoid duplicating folder name when joining path.
-	base := filepath.Base(g.directory)
-	path = strings.TrimPrefix(path, base)
-	return filepath.Join(g.directory, path)
+// absPath returns the absolute path of a path relative to the top-level directory as reported by git.
+func (g *git) absPath(name string) string {
+	return filepath.Join(g.root, filepath.FromSlash(name))
 }
@@ -0,0 +1,356 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testRepo is a throwaway git repository in a temporary directory.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &testRepo{t: t, dir: dir}
+	r.git("init", "--quiet", "--initial-branch=master")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=test",
+		"GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test",
+		"GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %s: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (r *testRepo) path(name string) string {
+	return filepath.Join(r.dir, filepath.FromSlash(name))
+}
+
+func (r *testRepo) write(name, content string) {
+	r.t.Helper()
+	if err := os.MkdirAll(filepath.Dir(r.path(name)), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(r.path(name), []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func (r *testRepo) remove(name string) {
+	r.t.Helper()
+	if err := os.Remove(r.path(name)); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// commit commits all changes of the work tree and returns the hash of the new commit.
+func (r *testRepo) commit(msg string) string {
+	r.t.Helper()
+	r.git("add", "--all")
+	r.git("commit", "--quiet", "--message", msg)
+	return r.git("rev-parse", "HEAD")
+}
+
+// backends returns all git backends for the repository.
+func (r *testRepo) backends() map[GitBackend]gitBackend {
+	r.t.Helper()
+	return r.backendsIn(".")
+}
+
+// backendsIn returns all git backends for the given directory in the repository, which does not need to exist.
+func (r *testRepo) backendsIn(name string) map[GitBackend]gitBackend {
+	r.t.Helper()
+	res := make(map[GitBackend]gitBackend, len(GitBackends))
+	for _, b := range GitBackends {
+		g, err := newGitBackend(r.t.Context(), b, r.path(name), defaultRemote)
+		if err != nil {
+			r.t.Fatalf("%s backend: %s", b, err)
+		}
+		res[b] = g
+	}
+	return res
+}
+
+// relativeChangedFiles formats the changed files like git diff --name-status with paths relative to the repository.
+func (r *testRepo) relativeChangedFiles(files []*ChangedFile) []string {
+	r.t.Helper()
+	rel := func(absPath string) string {
+		relPath, err := filepath.Rel(r.dir, absPath)
+		if err != nil {
+			r.t.Fatal(err)
+		}
+		return filepath.ToSlash(relPath)
+	}
+
+	res := make([]string, 0, len(files))
+	for _, f := range files {
+		s := string(f.Status) + " " + rel(f.Path)
+		if f.OldPath != "" {
+			s += " (from " + rel(f.OldPath) + ")"
+		}
+		res = append(res, s)
+	}
+	sort.Strings(res)
+	return res
+}
+
+const (
+	testChartYAML = "apiVersion: v2\nname: app\nversion: 1.0.0\n"
+	// testValuesYAML is long enough to be recognized as renamed when it is moved with a small change.
+	testValuesYAML = "replicas: 1\nimage:\n  repository: example.com/app\n  tag: 1.0.0\n  pullPolicy: IfNotPresent\nservice:\n  type: ClusterIP\n  port: 80\n"
+)
+
+func TestGitBackendsChangedFiles(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("app/Chart.yaml", testChartYAML)
+	r.write("app/values.yaml", testValuesYAML)
+	r.write("old/templates/cm.yaml", "kind: ConfigMap\ndata:\n  key: value\n")
+	r.write("unchanged/Chart.yaml", "apiVersion: v2\nname: unchanged\nversion: 0.1.0\n")
+	base := r.commit("initial")
+
+	// An unrelated file is added while another one is deleted, which must not be paired as rename.
+	r.remove("old/templates/cm.yaml")
+	r.write("new/Chart.yaml", "apiVersion: v1\ndescription: Something different\nname: new\nversion: 2.3.4\n")
+	// A renamed file with a small change.
+	r.remove("app/values.yaml")
+	r.write("moved/values.yaml", strings.Replace(testValuesYAML, "replicas: 1", "replicas: 2", 1))
+	r.write("app/Chart.yaml", strings.Replace(testChartYAML, "1.0.0", "1.0.1", 1))
+	head := r.commit("change")
+
+	expected := []string{
+		"added new/Chart.yaml",
+		"deleted old/templates/cm.yaml",
+		"modified app/Chart.yaml",
+		"renamed moved/values.yaml (from app/values.yaml)",
+	}
+	for name, g := range r.backends() {
+		files, err := g.getChangedFiles(t.Context(), base, head, UncommittedNone)
+		if err != nil {
+			t.Fatalf("%s backend: %s", name, err)
+		}
+		if actual := r.relativeChangedFiles(files); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s backend: expected changed files %q, got %q", name, expected, actual)
+		}
+	}
+}
+
+func TestGitBackendsUncommittedChanges(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("app/Chart.yaml", testChartYAML)
+	r.write("app/values.yaml", testValuesYAML)
+	r.write("other/Chart.yaml", "apiVersion: v2\nname: other\nversion: 0.1.0\n")
+	base := r.commit("initial")
+
+	r.write("app/templates/cm.yaml", "kind: ConfigMap\n")
+	head := r.commit("add template")
+
+	// Staged.
+	r.write("app/Chart.yaml", strings.Replace(testChartYAML, "1.0.0", "1.1.0", 1))
+	r.git("add", "app/Chart.yaml")
+	// Unstaged.
+	r.remove("other/Chart.yaml")
+	// Untracked.
+	r.write("new/Chart.yaml", "apiVersion: v2\nname: new\nversion: 0.0.1\n")
+
+	tests := []struct {
+		uncommitted UncommittedChanges
+		expected    []string
+	}{
+		{UncommittedNone, []string{
+			"added app/templates/cm.yaml",
+		}},
+		{UncommittedStaged, []string{
+			"added app/templates/cm.yaml",
+			"modified app/Chart.yaml",
+		}},
+		{UncommittedAll, []string{
+			"added app/templates/cm.yaml",
+			"added new/Chart.yaml",
+			"deleted other/Chart.yaml",
+			"modified app/Chart.yaml",
+		}},
+	}
+
+	for name, g := range r.backends() {
+		for _, tt := range tests {
+			files, err := g.getChangedFiles(t.Context(), base, head, tt.uncommitted)
+			if err != nil {
+				t.Fatalf("%s backend, %s: %s", name, tt.uncommitted, err)
+			}
+			if actual := r.relativeChangedFiles(files); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("%s backend, %s: expected changed files %q, got %q", name, tt.uncommitted, tt.expected, actual)
+			}
+		}
+	}
+}
+
+func TestGitBackendsMergeBase(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("app/Chart.yaml", testChartYAML)
+	forkPoint := r.commit("initial")
+
+	r.git("checkout", "--quiet", "-b", "feature")
+	r.write("app/values.yaml", testValuesYAML)
+	feature := r.commit("feature")
+
+	r.git("checkout", "--quiet", "master")
+	r.write("other/Chart.yaml", "apiVersion: v2\nname: other\nversion: 0.1.0\n")
+	r.commit("master")
+
+	for name, g := range r.backends() {
+		for _, args := range [][2]string{{"master", feature}, {feature, "master"}} {
+			actual, err := g.getMergeBase(t.Context(), args[0], args[1])
+			if err != nil {
+				t.Fatalf("%s backend: %s", name, err)
+			}
+			if actual != forkPoint {
+				t.Errorf("%s backend: expected merge base of %s and %s to be %s, got %s", name, args[0], args[1], forkPoint, actual)
+			}
+		}
+	}
+}
+
+func TestGitBackendsMergeBaseOfAncestor(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("app/Chart.yaml", testChartYAML)
+	master := r.commit("initial")
+
+	// The merge base is the compared commit itself, which is not the checked out one.
+	r.git("checkout", "--quiet", "-b", "feature")
+	r.write("app/values.yaml", testValuesYAML)
+	r.commit("feature")
+
+	for name, g := range r.backends() {
+		actual, err := g.getMergeBase(t.Context(), "feature", master)
+		if err != nil {
+			t.Fatalf("%s backend: %s", name, err)
+		}
+		if actual != master {
+			t.Errorf("%s backend: expected merge base %s, got %s", name, master, actual)
+		}
+	}
+}
+
+func TestGitBackendsReadFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("app/Chart.yaml", testChartYAML)
+	base := r.commit("initial")
+
+	changedChartYAML := strings.Replace(testChartYAML, "1.0.0", "2.0.0", 1)
+	r.write("app/Chart.yaml", changedChartYAML)
+	r.write("app/values.yaml", testValuesYAML)
+	head := r.commit("change")
+
+	r.write("app/values.yaml", "replicas: 3\n")
+
+	tests := []struct {
+		rev      string
+		name     string
+		expected string
+	}{
+		{base, "app/Chart.yaml", testChartYAML},
+		{base, "app/values.yaml", ""},
+		{head, "app/Chart.yaml", changedChartYAML},
+		{head, "app/values.yaml", testValuesYAML},
+		{"HEAD", "app/values.yaml", testValuesYAML},
+		{worktreeRevision, "app/values.yaml", "replicas: 3\n"},
+		{worktreeRevision, "app/missing.yaml", ""},
+	}
+
+	for name, g := range r.backends() {
+		for _, tt := range tests {
+			data, err := g.readFile(t.Context(), tt.rev, r.path(tt.name))
+			if tt.expected == "" {
+				if !errors.Is(err, errFileNotFound) {
+					t.Errorf("%s backend: expected %s at %q to not exist, got %q, %v", name, tt.name, tt.rev, data, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s backend: %s", name, err)
+			}
+			// The exec backend trims the output of git show.
+			if actual := strings.TrimSpace(string(data)); actual != strings.TrimSpace(tt.expected) {
+				t.Errorf("%s backend: expected %s at %q to be %q, got %q", name, tt.name, tt.rev, tt.expected, actual)
+			}
+		}
+	}
+}
+
+func TestGitBackendsSubdirectory(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a/b/keep/Chart.yaml", "apiVersion: v2\nname: keep\nversion: 0.1.0\n")
+	r.write("b/other/Chart.yaml", "apiVersion: v2\nname: other\nversion: 0.1.0\n")
+	base := r.commit("initial")
+
+	r.write("a/b/app/Chart.yaml", testChartYAML)
+	r.write("b/other/values.yaml", testValuesYAML)
+	added := r.commit("add")
+
+	for name, g := range r.backendsIn("a/b") {
+		files, err := g.getChangedFiles(t.Context(), base, added, UncommittedNone)
+		if err != nil {
+			t.Fatalf("%s backend: %s", name, err)
+		}
+		expected := []string{"added a/b/app/Chart.yaml"}
+		if actual := r.relativeChangedFiles(files); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s backend: expected changed files %q, got %q", name, expected, actual)
+		}
+
+		data, err := g.readFile(t.Context(), added, r.path("a/b/app/Chart.yaml"))
+		if err != nil {
+			t.Fatalf("%s backend: %s", name, err)
+		}
+		if actual := strings.TrimSpace(string(data)); actual != strings.TrimSpace(testChartYAML) {
+			t.Errorf("%s backend: expected a/b/app/Chart.yaml to be %q, got %q", name, testChartYAML, actual)
+		}
+	}
+
+	// The directory itself is removed from the work tree.
+	if err := os.RemoveAll(r.path("a")); err != nil {
+		t.Fatal(err)
+	}
+	removed := r.commit("remove")
+
+	for name, g := range r.backendsIn("a/b") {
+		files, err := g.getChangedFiles(t.Context(), added, removed, UncommittedNone)
+		if err != nil {
+			t.Fatalf("%s backend: %s", name, err)
+		}
+		expected := []string{"deleted a/b/app/Chart.yaml", "deleted a/b/keep/Chart.yaml"}
+		if actual := r.relativeChangedFiles(files); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s backend: expected changed files %q, got %q", name, expected, actual)
+		}
+	}
+}
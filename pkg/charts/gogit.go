@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// goGit is the gitBackend reading the repository directly without the git binary.
+type goGit struct {
+	remote    string
+	directory string
+	// root is the top-level directory of the work tree.
+	root string
+	repo *gogit.Repository
+}
+
+func newGoGit(directory, remote string) (*goGit, error) {
+	repo, err := gogit.PlainOpenWithOptions(directory, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		if errors.Is(err, gogit.ErrRepositoryNotExists) {
+			return nil, errNoGitRepository
+		}
+		return nil, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, errNoGitRepository
+	}
+
+	return &goGit{
+		remote:    remote,
+		directory: directory,
+		root:      wt.Filesystem.Root(),
+		repo:      repo,
+	}, nil
+}
+
+func (g *goGit) fetch(ctx context.Context, branch string) error {
+	remote, err := g.repo.Remote(g.remote)
+	if err != nil {
+		return errNoRemote
+	}
+
+	err = remote.FetchContext(ctx, &gogit.FetchOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(fetchRefSpec(g.remote, branch))},
+	})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+func (g *goGit) getChangedFiles(ctx context.Context, base, commit string, uncommitted UncommittedChanges) ([]*ChangedFile, error) {
+	if uncommitted == UncommittedStaged || uncommitted == UncommittedAll {
+		commit = "HEAD"
+	}
+
+	fromTree, err := g.resolveTree(base)
+	if err != nil {
+		return nil, err
+	}
+
+	toTree, err := g.resolveTree(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	// The default options only pair files as renames above a similarity threshold like git diff --find-renames.
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var changedFiles []*ChangedFile
+	for _, c := range changes {
+		f := &ChangedFile{Status: FileModified}
+		switch {
+		case c.From.Name == "":
+			f.Status = FileAdded
+			f.Path = c.To.Name
+		case c.To.Name == "":
+			f.Status = FileDeleted
+			f.Path = c.From.Name
+		case c.From.Name != c.To.Name:
+			f.Status = FileRenamed
+			f.Path = c.To.Name
+			f.OldPath = g.absPath(c.From.Name)
+		default:
+			f.Path = c.To.Name
+		}
+		f.Path = g.absPath(f.Path)
+
+		// Like git diff -- <directory>: files renamed out of the directory are reported as deleted.
+		if f.Status == FileRenamed && !g.isInDirectory(f.Path) && g.isInDirectory(f.OldPath) {
+			f = &ChangedFile{Status: FileDeleted, Path: f.OldPath}
+		}
+		if g.isInDirectory(f.Path) {
+			changedFiles = append(changedFiles, f)
+		}
+	}
+
+	if uncommitted == UncommittedStaged || uncommitted == UncommittedAll {
+		return g.addUncommittedChanges(ctx, fromTree, changedFiles, uncommitted)
+	}
+	return changedFiles, nil
+}
+
+// addUncommittedChanges merges the status of the work tree into the files changed between the base and HEAD.
+// Unlike git diff, go-git does not detect renames in the work tree, so they are reported as deleted and added files.
+func (g *goGit) addUncommittedChanges(ctx context.Context, baseTree *object.Tree, changedFiles []*ChangedFile, uncommitted UncommittedChanges) ([]*ChangedFile, error) {
+	// Computing the status cannot be cancelled, so at least do not start it.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*ChangedFile, len(changedFiles))
+	for _, f := range changedFiles {
+		byPath[f.Path] = f
+	}
+
+	for name, s := range status {
+		code := s.Staging
+		if uncommitted == UncommittedAll && s.Worktree != gogit.Unmodified {
+			code = s.Worktree
+		}
+		if code == gogit.Unmodified || (uncommitted == UncommittedStaged && code == gogit.Untracked) {
+			continue
+		}
+
+		absPath := g.absPath(name)
+		if !g.isInDirectory(absPath) {
+			continue
+		}
+
+		_, err := baseTree.File(name)
+		existsAtBase := err == nil
+		existsNow := code != gogit.Deleted
+		switch {
+		case existsAtBase && existsNow:
+			byPath[absPath] = &ChangedFile{Status: FileModified, Path: absPath}
+		case existsAtBase:
+			byPath[absPath] = &ChangedFile{Status: FileDeleted, Path: absPath}
+		case existsNow:
+			byPath[absPath] = &ChangedFile{Status: FileAdded, Path: absPath}
+		default:
+			// Added after the base and removed again.
+			delete(byPath, absPath)
+		}
+	}
+
+	res := make([]*ChangedFile, 0, len(byPath))
+	for _, f := range byPath {
+		res = append(res, f)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res, nil
+}
+
+func (g *goGit) absPath(name string) string {
+	return filepath.Join(g.root, filepath.FromSlash(name))
+}
+
+func (g *goGit) isInDirectory(absPath string) bool {
+	return absPath == g.directory || strings.HasPrefix(absPath, g.directory+string(filepath.Separator))
+}
+
+func (g *goGit) getCommitHash(_ context.Context, commit string) (string, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (g *goGit) getMergeBase(ctx context.Context, commit1, commit2 string) (string, error) {
+	c1, err := g.resolveCommit(commit1)
+	if err != nil {
+		return "", err
+	}
+
+	c2, err := g.resolveCommit(commit2)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	bases, err := c1.MergeBase(c2)
+	if err != nil {
+		return "", err
+	}
+	if len(bases) == 0 {
+		return "", fmt.Errorf("no merge base found for %s and %s", commit1, commit2)
+	}
+
+	return bases[0].Hash.String(), nil
+}
+
+func (g *goGit) readFile(_ context.Context, rev, absPath string) ([]byte, error) {
+	if rev == worktreeRevision {
+		return readWorkTreeFile(absPath)
+	}
+
+	relPath, err := filepath.Rel(g.root, absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := g.resolveTree(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := tree.File(filepath.ToSlash(relPath))
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, errFileNotFound
+		}
+		return nil, err
+	}
+
+	content, err := f.Contents()
+	return []byte(content), err
+}
+
+func (g *goGit) resolveCommit(rev string) (*object.Commit, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, err
+	}
+	return g.repo.CommitObject(*hash)
+}
+
+func (g *goGit) resolveTree(rev string) (*object.Tree, error) {
+	c, err := g.resolveCommit(rev)
+	if err != nil {
+		return nil, err
+	}
+	return c.Tree()
+}
@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DependencyGraph describes the dependencies between the charts of a folder.
+type DependencyGraph struct {
+	Nodes []*GraphNode `json:"nodes"`
+	Edges []*GraphEdge `json:"edges"`
+}
+
+// GraphNode is a chart in the DependencyGraph.
+// Remote dependencies and local dependencies outside the folder are external nodes without a path.
+type GraphNode struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Version    string `json:"version,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Repository string `json:"repository,omitempty"`
+	External   bool   `json:"external"`
+	Changed    bool   `json:"changed,omitempty"`
+}
+
+// GraphEdge points from a chart to one of its dependencies.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Version is the version constraint of the dependency.
+	Version string `json:"version,omitempty"`
+}
+
+// BuildDependencyGraph resolves the local and remote dependencies of all Helm charts in the given folder.
+// Charts whose metadata cannot be loaded are reported as warnings.
+func BuildDependencyGraph(ctx context.Context, folder string, excludeDirs []string, isUseRelativePath bool) (*DependencyGraph, []Warning, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allCharts, warnings, err := listHelmCharts(ctx, folder, excludeDirs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodeID := func(absPath string) (string, error) {
+		if !isUseRelativePath {
+			return absPath, nil
+		}
+		return filepath.Rel(folder, absPath)
+	}
+
+	nodes := make(map[string]*GraphNode)
+	for _, c := range allCharts {
+		id, err := nodeID(c.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		nodes[c.Path] = &GraphNode{
+			ID:      id,
+			Name:    c.Name,
+			Version: c.Version.String(),
+			Path:    id,
+		}
+	}
+
+	g := &DependencyGraph{}
+	for _, c := range allCharts {
+		from := nodes[c.Path]
+		for _, d := range c.Dependencies {
+			key := resolveLocalDependency(c.Path, d)
+			id := key
+			if key == "" {
+				key = strings.TrimSuffix(d.Repository, "/") + "/" + d.Name
+				id = key
+			} else if id, err = nodeID(key); err != nil {
+				return nil, nil, err
+			}
+
+			to, ok := nodes[key]
+			if !ok {
+				to = &GraphNode{
+					ID:         id,
+					Name:       d.Name,
+					Repository: d.Repository,
+					External:   true,
+				}
+				nodes[key] = to
+			}
+
+			g.Edges = append(g.Edges, &GraphEdge{
+				From:    from.ID,
+				To:      to.ID,
+				Version: d.Version,
+			})
+		}
+	}
+
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.SliceStable(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+
+	return g, warnings, nil
+}
+
+// MarkChanged marks the nodes of the given charts as changed. The paths of the charts must
+// match the node IDs, i.e. both have to be either absolute or relative to the same folder.
+func (g *DependencyGraph) MarkChanged(changed []*HelmChart) {
+	paths := make(map[string]bool, len(changed))
+	for _, c := range changed {
+		paths[c.Path] = true
+	}
+	for _, n := range g.Nodes {
+		if !n.External && paths[n.Path] {
+			n.Changed = true
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package charts
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -21,9 +22,25 @@ const chartMetadataName = "Chart.yaml"
 
 // HelmChart is used to report the results of below functions.
 type HelmChart struct {
-	Name    string
-	Version *semver.Version
-	Path    string
+	Name         string
+	Version      *semver.Version
+	Path         string
+	APIVersion   string
+	Type         string
+	KubeVersion  string
+	Dependencies []*chartutil.Dependency
+
+	// Reason and Via are only set by ListChangedHelmChartsInFolder and IncludeDependentHelmCharts.
+	// Via is the name of the dependency through which the chart was included.
+	Reason ChangeReason
+	Via    string
+	// Files are the changed files with paths relative to the chart. Only set by ListChangedHelmChartsInFolder.
+	Files []*ChangedFile
+}
+
+// IsLibrary returns true for library charts, which cannot be deployed on their own.
+func (h *HelmChart) IsLibrary() bool {
+	return h.Type == ChartTypeLibrary
 }
 
 // Equal checks if the given charts are equal.
@@ -32,174 +49,312 @@ func (h *HelmChart) Equal(c *HelmChart) bool {
 }
 
 // ListHelmChartsInFolder list all Helm charts in the given folder.
+// See ListCharts for a variant that can be cancelled and does not fail on invalid charts.
 func ListHelmChartsInFolder(folder string, excludeDirs []string, isUseRelativePath bool) ([]*HelmChart, error) {
-	folder, err := filepath.Abs(folder)
+	charts, warnings, err := listHelmCharts(context.Background(), folder, excludeDirs, isUseRelativePath)
 	if err != nil {
 		return nil, err
 	}
+	if len(warnings) > 0 {
+		return nil, warnings[0].Err
+	}
+	return charts, nil
+}
 
-	var charts []*HelmChart
-	err = filepath.Walk(folder, func(absPath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// ListChangedHelmChartsInFolder compares the current version against the given remote/branch:commit and lists the changed Helm charts.
+func ListChangedHelmChartsInFolder(rootDirectory string, excludeDirs []string, remote, branch, commit string, isUseRelativePath bool) ([]*HelmChart, error) {
+	return ListChangedHelmChartsInFolderWithGitOptions(GitOptions{Backend: GitBackendExec}, rootDirectory, excludeDirs, remote, branch, commit, isUseRelativePath)
+}
 
-		if info.IsDir() && isValidChartDirectory(absPath, excludeDirs) {
-			c, err := loadChartMetadata(absPath)
-			if err != nil {
-				return err
-			}
+// ListChangedHelmChartsInFolderWithGitOptions is like ListChangedHelmChartsInFolder but accesses the git repository as configured by the given options.
+// Warnings are printed to stderr. See ListChangedCharts for a variant that returns them and can be cancelled.
+func ListChangedHelmChartsInFolderWithGitOptions(opts GitOptions, rootDirectory string, excludeDirs []string, remote, branch, commit string, isUseRelativePath bool) ([]*HelmChart, error) {
+	changed, err := listChangedHelmCharts(context.Background(), opts, rootDirectory, excludeDirs, remote, branch, commit, false)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(changed.warnings)
 
-			if isUseRelativePath {
-				relPath, err := filepath.Rel(folder, c.Path)
-				if err != nil {
-					return err
-				}
-				c.Path = relPath
-			}
+	if isUseRelativePath {
+		return relativeHelmCharts(rootDirectory, changed.charts), nil
+	}
+	return changed.charts, nil
+}
 
-			if !containsChart(charts, c) {
-				charts = append(charts, c)
-			}
+// relativeHelmCharts makes the paths of the given charts relative to the root directory.
+// Charts whose path cannot be made relative are omitted.
+func relativeHelmCharts(rootDirectory string, charts []*HelmChart) []*HelmChart {
+	var res []*HelmChart
+	for _, c := range charts {
+		relPath, err := filepath.Rel(rootDirectory, c.Path)
+		if err != nil {
+			continue
 		}
-		return nil
-	})
+		c.Path = relPath
+		res = append(res, c)
+	}
+	return res
+}
 
-	return sortChartsAlphabetically(charts), err
+// changeSet is the result of comparing a commit against its merge base with the remote branch.
+type changeSet struct {
+	git gitBackend
+	// base is the revision compared against, usually the merge base.
+	base string
+	// head is the hash of the compared commit.
+	head string
+	// target is the compared commit or worktreeRevision if uncommitted changes are included.
+	target string
+	// charts contains the changed charts with absolute paths.
+	charts []*HelmChart
+	// removed contains the removed and moved charts with absolute paths.
+	removed []*RemovedHelmChart
+	// warnings contains the problems that did not prevent listing the changed charts.
+	warnings []Warning
 }
 
-// ListChangedHelmChartsInFolder compares the current version against the given remote/branch:commit and lists the changed Helm charts.
-func ListChangedHelmChartsInFolder(rootDirectory string, excludeDirs []string, remote, branch, commit string, isUseRelativePath bool) ([]*HelmChart, error) {
-	git, err := newGit(rootDirectory, remote)
+// listChangedHelmCharts compares the commit against the remote branch or the configured base.
+// Removed and moved charts are only looked up if requested, which requires reading the base revision.
+func listChangedHelmCharts(ctx context.Context, opts GitOptions, rootDirectory string, excludeDirs []string, remote, branch, commit string, includeRemoved bool) (*changeSet, error) {
+	git, err := newGitBackend(ctx, opts.Backend, rootDirectory, remote)
 	if err != nil {
 		return nil, err
 	}
 
-	err = git.fetch()
+	if opts.Head != "" {
+		commit = opts.Head
+	}
+	uncommitted := opts.Uncommitted
+	if uncommitted == "" {
+		uncommitted = UncommittedNone
+	}
+	if !slices.Contains(UncommittedModes, uncommitted) {
+		return nil, fmt.Errorf("unknown mode for uncommitted changes %q", uncommitted)
+	}
+	if uncommitted != UncommittedNone && commit != "HEAD" {
+		return nil, fmt.Errorf("uncommitted changes can only be included when comparing HEAD, not %s", commit)
+	}
+
+	baseRef := opts.Base
+	if baseRef == "" {
+		baseRef = fmt.Sprintf("%s/%s", remote, branch)
+		if !opts.NoFetch {
+			err = git.fetch(ctx, branch)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	commitHash, err := git.getCommitHash(ctx, commit)
 	if err != nil {
 		return nil, err
 	}
 
-	commitHash, err := git.getCommitHash(commit)
+	var base string
+	switch opts.DiffMode {
+	case DiffModeThreeDot, "":
+		base, err = git.getMergeBase(ctx, baseRef, commitHash)
+	case DiffModeTwoDot:
+		base, err = git.getCommitHash(ctx, baseRef)
+	default:
+		err = fmt.Errorf("unknown diff mode %q", opts.DiffMode)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	mergeBase, err := git.getMergeBase(fmt.Sprintf("%s/%s", remote, branch), commitHash)
+	target := commitHash
+	if uncommitted != UncommittedNone {
+		target = worktreeRevision
+	}
+
+	changedFiles, err := git.getChangedFiles(ctx, base, commitHash, uncommitted)
 	if err != nil {
 		return nil, err
 	}
 
-	changedDirs, err := git.getChangedDirs(mergeBase, commitHash)
+	rules, err := newExcludeRules(rootDirectory, excludeDirs)
 	if err != nil {
 		return nil, err
 	}
 
-	var res []*HelmChart
-	for _, dir := range changedDirs {
-		chartPath, err := getChartRootDirectory(rootDirectory, dir, excludeDirs)
+	var (
+		res      []*HelmChart
+		warnings []Warning
+	)
+	// The cache is only read, since saving it would drop the entries of all charts that did not change.
+	cache := openDiscoveryCache(rootDirectory)
+	chartsByPath := make(map[string]*HelmChart)
+	chartOf := func(absPath string) *HelmChart {
+		chartPath, err := getChartRootDirectory(rootDirectory, absPath, rules)
 		if err != nil {
-			continue
+			return nil
+		}
+		if c, ok := chartsByPath[chartPath]; ok {
+			return c
 		}
 
-		c, err := loadChartMetadata(chartPath)
+		c, err := cache.loadChartMetadata(chartPath)
 		if err != nil {
-			fmt.Printf("failed to load chart metadata: %s\n", err.Error())
-			continue
+			warnings = append(warnings, newChartMetadataWarning(chartPath, err))
+			chartsByPath[chartPath] = nil
+			return nil
+		}
+		c.Reason = ChangeReasonChanged
+		chartsByPath[chartPath] = c
+		res = append(res, c)
+		return c
+	}
+
+	var sharedFiles []*ChangedFile
+	for _, f := range changedFiles {
+		c := chartOf(f.Path)
+		if c != nil {
+			c.Files = append(c.Files, relativeChangedFile(c.Path, f))
+		} else if _, err := getChartRootDirectory(rootDirectory, f.Path, rules); err != nil {
+			sharedFiles = append(sharedFiles, f)
 		}
 
-		if isUseRelativePath {
-			relPath, err := filepath.Rel(rootDirectory, c.Path)
-			if err != nil {
-				continue
+		// A file moved to another chart also changes the chart it was moved from.
+		if f.Status == FileRenamed {
+			if from := chartOf(f.OldPath); from != nil && from != c {
+				from.Files = append(from.Files, relativeChangedFile(from.Path, &ChangedFile{Status: FileDeleted, Path: f.OldPath}))
 			}
-			c.Path = relPath
 		}
+	}
+
+	if len(opts.SharedFiles) > 0 && len(sharedFiles) > 0 {
+		var sharedWarnings []Warning
+		res, sharedWarnings, err = addChartsAffectedBySharedFiles(ctx, rootDirectory, excludeDirs, opts.SharedFiles, sharedFiles, res)
+		if err != nil {
+			return nil, err
+		}
+		warnings = appendWarnings(warnings, sharedWarnings...)
+	}
+
+	var removed []*RemovedHelmChart
+	if includeRemoved {
+		var removedWarnings []Warning
+		removed, removedWarnings, err = findRemovedHelmCharts(ctx, git, rootDirectory, rules, base, target, changedFiles, res)
+		if err != nil {
+			return nil, err
+		}
+		warnings = appendWarnings(warnings, removedWarnings...)
+	}
+
+	return &changeSet{
+		git:      git,
+		base:     base,
+		head:     commitHash,
+		target:   target,
+		charts:   sortChartsAlphabetically(res),
+		removed:  removed,
+		warnings: warnings,
+	}, nil
+}
+
+// relativeChangedFile returns a copy of the changed file with paths relative to the chart directory.
+func relativeChangedFile(chartPath string, f *ChangedFile) *ChangedFile {
+	res := *f
+	if relPath, err := filepath.Rel(chartPath, f.Path); err == nil {
+		res.Path = relPath
+	}
+	if f.OldPath != "" {
+		if relPath, err := filepath.Rel(chartPath, f.OldPath); err == nil {
+			res.OldPath = relPath
+		}
+	}
+	return &res
+}
 
-		if !containsChart(res, c) {
+// FilterLibraryCharts removes library charts from the given list.
+func FilterLibraryCharts(charts []*HelmChart) []*HelmChart {
+	res := make([]*HelmChart, 0, len(charts))
+	for _, c := range charts {
+		if !c.IsLibrary() {
 			res = append(res, c)
 		}
 	}
-	return sortChartsAlphabetically(res), nil
+	return res
 }
 
 // FindDuplicateChartsInFolder find duplicate Helm charts in the given folder.
+// A Helm chart is considered a duplicate if the chart names are equivalent but not the paths.
+// See FindDuplicateChartGroupsInFolder to get the charts grouped by name.
 func FindDuplicateChartsInFolder(folder string, excludeDirs []string, isUseRelativePath bool) ([]*HelmChart, error) {
-	foundCharts, err := ListHelmChartsInFolder(folder, excludeDirs, isUseRelativePath)
+	groups, err := FindDuplicateChartGroupsInFolder(folder, excludeDirs, isUseRelativePath)
 	if err != nil {
 		return nil, err
 	}
 
-	// A Helm chart is considering a duplicate if the chart names are equivalent but not the path'.
 	dups := make([]*HelmChart, 0)
-	for _, i := range foundCharts {
-		for _, j := range foundCharts {
-			if i.Name == j.Name && i.Path != j.Path {
-				dups = append(dups, i)
-			}
-		}
+	for _, g := range groups {
+		dups = append(dups, g.Charts...)
 	}
-	return sortChartsAlphabetically(dups), nil
+	return dups, nil
 }
 
 func loadChartMetadata(absPathChartFolder string) (*HelmChart, error) {
-	meta, err := chartutil.LoadChartfile(path.Join(absPathChartFolder, chartMetadataName))
+	data, err := os.ReadFile(path.Join(absPathChartFolder, chartMetadataName))
 	if err != nil {
 		return nil, err
 	}
 
-	version, err := semver.NewVersion(meta.GetVersion())
+	meta, err := parseChartMetadata(data)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", absPathChartFolder, err)
+	}
+
+	version, err := semver.NewVersion(meta.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: version %q: %w", absPathChartFolder, meta.Version, err)
+	}
+
+	deps, err := loadChartDependencies(absPathChartFolder, meta)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", absPathChartFolder, err)
 	}
 
 	return &HelmChart{
-		Name:    meta.GetName(),
-		Version: version,
-		Path:    absPathChartFolder,
+		Name:         meta.Name,
+		Version:      version,
+		Path:         absPathChartFolder,
+		APIVersion:   meta.APIVersion,
+		Type:         meta.Type,
+		KubeVersion:  meta.KubeVersion,
+		Dependencies: deps,
 	}, nil
 }
 
-func isValidChartDirectory(absPath string, excludeDirs []string) bool {
-	if !filepath.IsAbs(absPath) {
+func isValidChartDirectory(absPath string, rules *excludeRules) bool {
+	if !filepath.IsAbs(absPath) || rules.isExcluded(absPath) {
 		return false
 	}
 
-	for _, e := range excludeDirs {
-		if slices.Contains(strings.Split(absPath, string(filepath.Separator)), e) {
-			return false
-		}
-	}
-
 	_, err := os.Stat(path.Join(absPath, chartMetadataName))
 	return err == nil
 }
 
-func getChartRootDirectory(root, chartPath string, excludedDirs []string) (string, error) {
-	if chartPath == root {
+func getChartRootDirectory(root, chartPath string, rules *excludeRules) (string, error) {
+	if chartPath == root || !strings.HasPrefix(chartPath, root+string(filepath.Separator)) {
 		return "", errors.New("no more parent directories")
 	}
 
-	if isValidChartDirectory(chartPath, excludedDirs) {
+	if isValidChartDirectory(chartPath, rules) {
 		return chartPath, nil
 	}
 
-	return getChartRootDirectory(root, filepath.Dir(chartPath), excludedDirs)
-}
-
-// containsCharts is used to avoid duplicates in a list of HelmCharts.
-func containsChart(charts []*HelmChart, chart *HelmChart) bool {
-	for _, c := range charts {
-		if c.Equal(chart) {
-			return true
-		}
-	}
-	return false
+	return getChartRootDirectory(root, filepath.Dir(chartPath), rules)
 }
 
+// sortChartsAlphabetically sorts the charts by name and charts with the same name by path.
 func sortChartsAlphabetically(charts []*HelmChart) []*HelmChart {
 	sort.Slice(charts, func(i, j int) bool {
-		return charts[i].Name < charts[j].Name
+		if charts[i].Name != charts[j].Name {
+			return charts[i].Name < charts[j].Name
+		}
+		return charts[i].Path < charts[j].Path
 	})
 	return charts
 }
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ChartsIgnoreFileName is the name of the file in the scanned root directory containing additional exclude patterns.
+const ChartsIgnoreFileName = ".helmchartsignore"
+
+// excludeRules decide which directories are excluded using gitignore-style patterns relative to the root directory:
+//   - Patterns without a slash match a directory of that name at any depth, e.g. "test".
+//   - Patterns containing a slash are anchored to the root directory, e.g. "/test" or "system/test".
+//   - "*", "?" and "[...]" match within a path segment, "**" matches any number of segments, e.g. "**/examples/*".
+//   - A leading "!" re-includes directories excluded by a previous pattern, unless a parent directory is excluded.
+//
+// The patterns of the ignore file are applied before the given ones.
+type excludeRules struct {
+	root     string
+	patterns []*excludePattern
+}
+
+type excludePattern struct {
+	negate   bool
+	anchored bool
+	segments []string
+}
+
+func newExcludeRules(root string, patterns []string) (*excludeRules, error) {
+	filePatterns, err := readChartsIgnoreFile(filepath.Join(root, ChartsIgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+	return newPathRules(root, append(filePatterns, patterns...))
+}
+
+// newPathRules uses the same pattern syntax as excludeRules but does not read the ignore file.
+func newPathRules(root string, patterns []string) (*excludeRules, error) {
+	r := &excludeRules{root: root}
+	for _, p := range patterns {
+		pattern, err := parseExcludePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		if pattern != nil {
+			r.patterns = append(r.patterns, pattern)
+		}
+	}
+	return r, nil
+}
+
+func readChartsIgnoreFile(fileName string) ([]string, error) {
+	f, err := os.Open(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+func parseExcludePattern(p string) (*excludePattern, error) {
+	p = strings.TrimSpace(p)
+	pattern := &excludePattern{}
+	if strings.HasPrefix(p, "!") {
+		pattern.negate = true
+		p = p[1:]
+	}
+
+	// Only directories are matched, so a trailing slash does not change anything.
+	p = strings.TrimSuffix(filepath.ToSlash(p), "/")
+	if p == "" {
+		return nil, nil
+	}
+
+	pattern.anchored = strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	pattern.segments = strings.Split(p, "/")
+
+	for _, s := range pattern.segments {
+		if _, err := path.Match(s, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", p, err)
+		}
+	}
+	return pattern, nil
+}
+
+// isExcluded returns true if the directory or one of its parents below the root directory is excluded.
+func (r *excludeRules) isExcluded(absPath string) bool {
+	if len(r.patterns) == 0 {
+		return false
+	}
+
+	relPath, err := filepath.Rel(r.root, absPath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return false
+	}
+
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i := 1; i <= len(segments); i++ {
+		if r.matches(segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches applies all patterns to the given path. The last matching pattern wins.
+func (r *excludeRules) matches(segments []string) bool {
+	excluded := false
+	for _, p := range r.patterns {
+		if p.match(segments) {
+			excluded = !p.negate
+		}
+	}
+	return excluded
+}
+
+func (p *excludePattern) match(segments []string) bool {
+	if !p.anchored {
+		ok, _ := path.Match(p.segments[0], segments[len(segments)-1])
+		return ok
+	}
+	return matchSegments(p.segments, segments)
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExcludeRules(t *testing.T) {
+	root := t.TempDir()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		excluded []string
+		included []string
+	}{
+		{
+			name:     "unanchored names match at any depth",
+			patterns: []string{"test"},
+			excluded: []string{"test", "system/test", "system/test/chart", "a/b/test"},
+			included: []string{"system", "testing", "system/tests"},
+		},
+		{
+			name:     "wildcards match within a segment",
+			patterns: []string{"*-old"},
+			excluded: []string{"keystone-old", "openstack/nova-old"},
+			included: []string{"keystone", "old"},
+		},
+		{
+			name:     "leading slash anchors to the root",
+			patterns: []string{"/test"},
+			excluded: []string{"test", "test/chart"},
+			included: []string{"system/test"},
+		},
+		{
+			name:     "patterns with a slash are anchored",
+			patterns: []string{"system/test"},
+			excluded: []string{"system/test", "system/test/chart"},
+			included: []string{"other/system/test", "system"},
+		},
+		{
+			name:     "double star matches any number of segments",
+			patterns: []string{"**/examples/*"},
+			excluded: []string{"examples/a", "system/examples/a", "a/b/examples/c/d"},
+			included: []string{"examples", "system/examples"},
+		},
+		{
+			name:     "trailing slash is ignored",
+			patterns: []string{"vendor/"},
+			excluded: []string{"vendor", "system/vendor"},
+		},
+		{
+			name:     "negation re-includes",
+			patterns: []string{"test", "!system/test"},
+			excluded: []string{"test", "other/test"},
+			included: []string{"system/test", "system/test/chart"},
+		},
+		{
+			name:     "negation applies in order",
+			patterns: []string{"!system/test", "test"},
+			excluded: []string{"system/test"},
+		},
+		{
+			name:     "negation does not re-include below an excluded parent",
+			patterns: []string{"vendor", "!vendor/keep"},
+			excluded: []string{"vendor", "vendor/keep", "vendor/keep/chart"},
+		},
+	}
+
+	for _, tt := range tests {
+		rules, err := newPathRules(root, tt.patterns)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		for _, p := range tt.excluded {
+			if !rules.isExcluded(filepath.Join(root, filepath.FromSlash(p))) {
+				t.Errorf("%s: expected %q to be excluded by %q", tt.name, p, tt.patterns)
+			}
+		}
+		for _, p := range tt.included {
+			if rules.isExcluded(filepath.Join(root, filepath.FromSlash(p))) {
+				t.Errorf("%s: expected %q not to be excluded by %q", tt.name, p, tt.patterns)
+			}
+		}
+	}
+}
+
+func TestExcludeRulesOutsideOfRoot(t *testing.T) {
+	root := t.TempDir()
+	rules, err := newPathRules(root, []string{"**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{root, filepath.Dir(root), filepath.Join(filepath.Dir(root), "other")} {
+		if rules.isExcluded(p) {
+			t.Errorf("expected %q not to be excluded", p)
+		}
+	}
+}
+
+func TestExcludeRulesInvalidPattern(t *testing.T) {
+	_, err := newPathRules(t.TempDir(), []string{"test", "system/[a"})
+	if err == nil || !strings.Contains(err.Error(), `invalid exclude pattern "system/[a"`) {
+		t.Errorf("expected an invalid pattern error, got %v", err)
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/b/c", false},
+		{"a/*", "a/b", true},
+		{"a/*", "a", false},
+		{"**", "a/b/c", true},
+		{"**/c", "c", true},
+		{"**/c", "a/b/c", true},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/b/b/c", true},
+		{"a/**/c", "b/c", false},
+		{"**/x/*", "x/y", true},
+		{"**/x/*", "a/x/y", true},
+		{"**/x/*", "a/x", false},
+		{"**/x/*", "a/x/y/z", false},
+	}
+
+	for _, tt := range tests {
+		actual := matchSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+		if actual != tt.expected {
+			t.Errorf("matchSegments(%q, %q): expected %t, got %t", tt.pattern, tt.path, tt.expected, actual)
+		}
+	}
+}
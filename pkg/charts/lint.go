@@ -0,0 +1,187 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"k8s.io/helm/pkg/lint"
+	"k8s.io/helm/pkg/lint/rules"
+	"k8s.io/helm/pkg/lint/support"
+)
+
+// Lint severities as used by the Helm lint rules.
+const (
+	LintSeverityInfo    = support.InfoSev
+	LintSeverityWarning = support.WarningSev
+	LintSeverityError   = support.ErrorSev
+)
+
+// LintOptions configure LintHelmCharts.
+type LintOptions struct {
+	// Values are used to render the templates.
+	Values []byte
+	// Namespace is used to render the templates.
+	Namespace string
+	// Strict fails on missing values while rendering.
+	Strict bool
+	// Workers is the maximum number of charts linted concurrently. Defaults to the number of CPUs.
+	Workers int
+}
+
+// LintResult contains the findings of linting a single chart.
+type LintResult struct {
+	Chart           *HelmChart
+	Messages        []support.Message
+	HighestSeverity int
+}
+
+// LintHelmCharts runs the Helm lint rules for the given charts using a bounded pool of workers.
+// Relative chart paths are resolved against the root directory. The results are in the order of the given charts.
+// Charts are not linted anymore once the context is done.
+func LintHelmCharts(ctx context.Context, rootDirectory string, charts []*HelmChart, opts LintOptions) ([]*LintResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]*LintResult, len(charts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(charts)) {
+		wg.Go(func() {
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				results[i] = lintHelmChart(rootDirectory, charts[i], opts)
+			}
+		})
+	}
+
+loop:
+	for i := range charts {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func lintHelmChart(rootDirectory string, chart *HelmChart, opts LintOptions) *LintResult {
+	chartPath := chart.Path
+	if !filepath.IsAbs(chartPath) {
+		chartPath = filepath.Join(rootDirectory, chartPath)
+	}
+
+	if chart.APIVersion != APIVersionV2 {
+		linter := lint.All(chartPath, opts.Values, opts.Namespace, opts.Strict)
+		return &LintResult{
+			Chart:           chart,
+			Messages:        linter.Messages,
+			HighestSeverity: linter.HighestSeverity,
+		}
+	}
+
+	// The Helm 2 lint rules reject apiVersion v2, so the templates are rendered from a copy of the chart
+	// declaring apiVersion v1 instead.
+	res := &LintResult{Chart: chart}
+	linter := support.Linter{ChartDir: chartPath}
+	rules.Chartfile(&linter)
+	rules.Values(&linter)
+	unsupportedAPIVersion := fmt.Sprintf("apiVersion '%s' is not valid", APIVersionV2)
+	for _, m := range linter.Messages {
+		if strings.HasPrefix(m.Err.Error(), unsupportedAPIVersion) {
+			continue
+		}
+		res.add(m)
+	}
+	for _, m := range lintV2Templates(chartPath, opts) {
+		res.add(m)
+	}
+	return res
+}
+
+func (r *LintResult) add(m support.Message) {
+	r.Messages = append(r.Messages, m)
+	r.HighestSeverity = max(r.HighestSeverity, m.Severity)
+}
+
+// chartAPIVersionV2 matches the apiVersion of a Chart.yaml declaring apiVersion v2.
+var chartAPIVersionV2 = regexp.MustCompile(`(?m)^apiVersion:\s*["']?v2["']?\s*$`)
+
+// lintV2Templates runs the Helm 2 template rules for a copy of the chart, in which the apiVersion of the chart and
+// its unpacked subcharts is replaced by v1. Template functions only known to Helm 3 are reported as errors.
+func lintV2Templates(chartPath string, opts LintOptions) []support.Message {
+	tmpDir, err := os.MkdirTemp("", "helm-charts-lint-")
+	if err != nil {
+		return []support.Message{support.NewMessage(support.ErrorSev, "templates/", err)}
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := copyChartAsV1(chartPath, tmpDir); err != nil {
+		return []support.Message{support.NewMessage(support.ErrorSev, "templates/", err)}
+	}
+
+	linter := support.Linter{ChartDir: tmpDir}
+	rules.Templates(&linter, opts.Values, opts.Namespace, opts.Strict)
+	return linter.Messages
+}
+
+func copyChartAsV1(chartPath, destination string) error {
+	return filepath.WalkDir(chartPath, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(chartPath, absPath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(destination, relPath)
+
+		switch {
+		case d.IsDir():
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0o755)
+		case !d.Type().IsRegular():
+			return nil
+		}
+
+		data, err := os.ReadFile(absPath)
+		if err != nil {
+			return err
+		}
+		if d.Name() == chartMetadataName {
+			data = chartAPIVersionV2.ReplaceAll(data, []byte("apiVersion: "+APIVersionV1))
+		}
+		return os.WriteFile(target, data, 0o644)
+	})
+}
+
+// HighestLintSeverity returns the worst severity of all results.
+func HighestLintSeverity(results []*LintResult) int {
+	highest := support.UnknownSev
+	for _, r := range results {
+		highest = max(highest, r.HighestSeverity)
+	}
+	return highest
+}
@@ -0,0 +1,123 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+)
+
+const (
+	// APIVersionV1 is the chart API version used by Helm 2.
+	APIVersionV1 = "v1"
+	// APIVersionV2 is the chart API version introduced with Helm 3.
+	APIVersionV2 = "v2"
+
+	// ChartTypeApplication is the default chart type.
+	ChartTypeApplication = "application"
+	// ChartTypeLibrary is used for charts that only provide helpers and cannot be installed.
+	ChartTypeLibrary = "library"
+)
+
+// chartMetadata contains the fields of a Chart.yaml (apiVersion v1 and v2) used by this plugin.
+type chartMetadata struct {
+	APIVersion   string                  `json:"apiVersion"`
+	Name         string                  `json:"name"`
+	Version      string                  `json:"version"`
+	KubeVersion  string                  `json:"kubeVersion,omitempty"`
+	Type         string                  `json:"type,omitempty"`
+	Dependencies []*chartutil.Dependency `json:"dependencies,omitempty"`
+}
+
+func parseChartMetadata(data []byte) (*chartMetadata, error) {
+	var meta chartMetadata
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+
+	// Helm 2 did not require the apiVersion to be set.
+	if meta.APIVersion == "" {
+		meta.APIVersion = APIVersionV1
+	}
+	// Helm 2 ignores the type, so charts with apiVersion v1 are always application charts.
+	if meta.Type == "" || meta.APIVersion == APIVersionV1 {
+		meta.Type = ChartTypeApplication
+	}
+
+	if err := meta.validate(); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+func (m *chartMetadata) validate() error {
+	if m.Name == "" {
+		return errors.New("chart metadata is missing the name")
+	}
+	if m.Version == "" {
+		return fmt.Errorf("chart %s is missing the version", m.Name)
+	}
+
+	switch m.APIVersion {
+	case APIVersionV1:
+		// The type is ignored, see parseChartMetadata.
+	case APIVersionV2:
+		if m.Type != ChartTypeApplication && m.Type != ChartTypeLibrary {
+			return fmt.Errorf("chart %s: invalid type %q", m.Name, m.Type)
+		}
+	default:
+		return fmt.Errorf("chart %s: unsupported apiVersion %q", m.Name, m.APIVersion)
+	}
+
+	return nil
+}
+
+// loadChartDependencies returns the dependencies of a chart.
+// Charts with apiVersion v1 declare them in the requirements.yaml, v2 charts in the Chart.yaml.
+func loadChartDependencies(absPathChartFolder string, meta *chartMetadata) ([]*chartutil.Dependency, error) {
+	if meta.APIVersion == APIVersionV2 {
+		return meta.Dependencies, nil
+	}
+
+	data, err := os.ReadFile(path.Join(absPathChartFolder, requirementsFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var req chartutil.Requirements
+	if err := yaml.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return req.Dependencies, nil
+}
+
+// FindChartMetadataLine returns the 1-based line of the given top-level key, e.g. version, in the Chart.yaml of
+// the chart or 0 if the key or the file does not exist.
+func FindChartMetadataLine(absPathChartFolder, key string) int {
+	data, err := os.ReadFile(filepath.Join(absPathChartFolder, chartMetadataName))
+	if err != nil {
+		return 0
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		k, _, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimRight(k, " \t") == key {
+			return line
+		}
+	}
+	return 0
+}
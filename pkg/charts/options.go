@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	defaultRemote = "origin"
+	defaultBranch = "master"
+	defaultCommit = "HEAD"
+)
+
+// ListOptions configure ListCharts.
+type ListOptions struct {
+	// ExcludeDirs are gitignore-style patterns of directories to exclude in addition to the ones in the ChartsIgnoreFileName.
+	ExcludeDirs []string
+	// UseRelativePath reports the paths of the charts relative to the root directory instead of absolute ones.
+	UseRelativePath bool
+	// SkipLibraryCharts omits library charts from the result.
+	SkipLibraryCharts bool
+}
+
+// ChangedOptions configure ListChangedCharts and CheckVersionBumps.
+type ChangedOptions struct {
+	ListOptions
+	GitOptions
+	// Remote is the name of the git remote used to identify changes. Defaults to origin.
+	Remote string
+	// Branch is the name of the branch used to identify changes. Defaults to master.
+	Branch string
+	// Commit is the compared commit. Defaults to HEAD.
+	Commit string
+	// IncludeDependents also lists charts depending on a changed chart via a local (file://) dependency.
+	IncludeDependents bool
+	// RollUpSubcharts reports changes of subcharts for the outermost deployable chart containing them.
+	RollUpSubcharts bool
+	// IncludeRemoved looks up the charts that were removed or moved since the base, see ChangedResult.Removed.
+	IncludeRemoved bool
+}
+
+func (o ChangedOptions) withDefaults() ChangedOptions {
+	if o.Remote == "" {
+		o.Remote = defaultRemote
+	}
+	if o.Branch == "" {
+		o.Branch = defaultBranch
+	}
+	if o.Commit == "" {
+		o.Commit = defaultCommit
+	}
+	return o
+}
+
+// Warning is a problem that did not prevent the operation, e.g. a chart whose metadata could not be loaded.
+type Warning struct {
+	// Path is the absolute path of the affected chart directory.
+	Path    string
+	Message string
+	// Err is the cause of the warning.
+	Err error
+}
+
+func newChartMetadataWarning(absPath string, err error) Warning {
+	return Warning{
+		Path:    absPath,
+		Message: fmt.Sprintf("failed to load chart metadata: %s", err.Error()),
+		Err:     err,
+	}
+}
+
+// String returns the message of the warning.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// appendWarnings appends the given warnings, skipping those already reported for the same chart,
+// since several steps may load the same broken chart.
+func appendWarnings(warnings []Warning, add ...Warning) []Warning {
+	for _, w := range add {
+		if !slices.ContainsFunc(warnings, func(o Warning) bool { return o.Path == w.Path && o.Message == w.Message }) {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// printWarnings is used by the functions predating the ones returning warnings.
+func printWarnings(warnings []Warning) {
+	for _, w := range warnings {
+		fmt.Fprintln(os.Stderr, w)
+	}
+}
+
+// ListResult is returned by ListCharts.
+type ListResult struct {
+	Charts []*HelmChart
+	// Warnings contains the charts that were skipped because their metadata could not be loaded.
+	Warnings []Warning
+}
+
+// ChangedResult is returned by ListChangedCharts.
+type ChangedResult struct {
+	// Base is the revision the changes are compared against, usually the merge base.
+	Base string
+	// Head is the hash of the compared commit. Uncommitted changes are compared on top of it if requested.
+	Head string
+	// Charts contains the changed charts with the reason why they are listed and their changed files.
+	Charts []*HelmChart
+	// Removed contains the charts that were removed or moved since the base. Only set if requested.
+	Removed []*RemovedHelmChart
+	// Warnings contains the problems that did not prevent listing the changed charts.
+	Warnings []Warning
+}
+
+// VersionBumpResult is returned by CheckVersionBumps.
+type VersionBumpResult struct {
+	// Base is the revision the versions are compared against, usually the merge base.
+	Base string
+	// Head is the hash of the compared commit.
+	Head     string
+	Bumps    []*VersionBump
+	Warnings []Warning
+}
+
+// ListCharts lists all Helm charts in the given folder.
+// Unlike ListHelmChartsInFolder, charts whose metadata cannot be loaded are reported as warnings instead of failing.
+func ListCharts(ctx context.Context, folder string, opts ListOptions) (*ListResult, error) {
+	charts, warnings, err := listHelmCharts(ctx, folder, opts.ExcludeDirs, opts.UseRelativePath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SkipLibraryCharts {
+		charts = FilterLibraryCharts(charts)
+	}
+	return &ListResult{Charts: charts, Warnings: warnings}, nil
+}
+
+// ListChangedCharts compares the given commit against the remote branch or the configured base and lists the changed
+// and removed Helm charts. The git operations are cancelled when the context is done.
+func ListChangedCharts(ctx context.Context, rootDirectory string, opts ChangedOptions) (*ChangedResult, error) {
+	opts = opts.withDefaults()
+	rootDirectory, err := filepath.Abs(rootDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := listChangedHelmCharts(ctx, opts.GitOptions, rootDirectory, opts.ExcludeDirs, opts.Remote, opts.Branch, opts.Commit, opts.IncludeRemoved)
+	if err != nil {
+		return nil, err
+	}
+
+	charts, warnings := changed.charts, changed.warnings
+	if opts.UseRelativePath {
+		charts = relativeHelmCharts(rootDirectory, charts)
+		if err := relativeRemovedHelmCharts(rootDirectory, changed.removed); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.RollUpSubcharts {
+		var rollUpWarnings []Warning
+		charts, rollUpWarnings, err = RollUpSubcharts(ctx, rootDirectory, charts, opts.ExcludeDirs, opts.UseRelativePath)
+		if err != nil {
+			return nil, err
+		}
+		warnings = appendWarnings(warnings, rollUpWarnings...)
+	}
+
+	if opts.IncludeDependents {
+		var dependentWarnings []Warning
+		charts, dependentWarnings, err = IncludeDependentHelmCharts(ctx, rootDirectory, charts, opts.ExcludeDirs, opts.UseRelativePath)
+		if err != nil {
+			return nil, err
+		}
+		warnings = appendWarnings(warnings, dependentWarnings...)
+	}
+
+	if opts.SkipLibraryCharts {
+		charts = FilterLibraryCharts(charts)
+	}
+
+	return &ChangedResult{
+		Base:     changed.base,
+		Head:     changed.head,
+		Charts:   charts,
+		Removed:  changed.removed,
+		Warnings: warnings,
+	}, nil
+}
+
+// CheckVersionBumps lists the changed Helm charts like ListChangedCharts and compares their version at the base
+// with the version at the compared commit. IncludeDependents, RollUpSubcharts and IncludeRemoved are ignored.
+func CheckVersionBumps(ctx context.Context, rootDirectory string, opts ChangedOptions) (*VersionBumpResult, error) {
+	opts = opts.withDefaults()
+	rootDirectory, err := filepath.Abs(rootDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := listChangedHelmCharts(ctx, opts.GitOptions, rootDirectory, opts.ExcludeDirs, opts.Remote, opts.Branch, opts.Commit, false)
+	if err != nil {
+		return nil, err
+	}
+
+	bumps, err := checkVersionBumps(ctx, changed, rootDirectory, opts.UseRelativePath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SkipLibraryCharts {
+		filtered := make([]*VersionBump, 0, len(bumps))
+		for _, b := range bumps {
+			if !b.Chart.IsLibrary() {
+				filtered = append(filtered, b)
+			}
+		}
+		bumps = filtered
+	}
+
+	return &VersionBumpResult{
+		Base:     changed.base,
+		Head:     changed.head,
+		Bumps:    bumps,
+		Warnings: changed.warnings,
+	}, nil
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DependencyCycleError is returned if the local dependencies of the charts form a cycle.
+type DependencyCycleError struct {
+	// Cycle contains the paths of the charts forming the cycle relative to the folder. The first one is repeated at the end.
+	Cycle []string
+}
+
+func (e *DependencyCycleError) Error() string {
+	return "dependency cycle detected: " + strings.Join(e.Cycle, " -> ")
+}
+
+// OrderHelmChartsInFolder sorts the Helm charts in the given folder topologically by their local (file://) dependencies.
+// The charts are grouped into levels: A chart only depends on charts of previous levels, so all charts of a level can be processed in parallel.
+// If selected is not nil, only those charts are ordered, e.g. the result of IncludeDependentHelmCharts.
+// Charts whose metadata cannot be loaded are reported as warnings.
+func OrderHelmChartsInFolder(ctx context.Context, folder string, selected []*HelmChart, excludeDirs []string, isUseRelativePath bool) ([][]*HelmChart, []Warning, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allCharts, warnings, err := listHelmCharts(ctx, folder, excludeDirs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	graph := newDependencyGraph(allCharts)
+
+	nodes := allCharts
+	if selected != nil {
+		nodes = make([]*HelmChart, 0, len(selected))
+		for _, c := range selected {
+			absPath := c.Path
+			if !filepath.IsAbs(absPath) {
+				absPath = filepath.Join(folder, absPath)
+			}
+			if node, ok := graph.charts[absPath]; ok {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	inSelection := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		inSelection[n.Path] = true
+	}
+
+	// Kahn's algorithm, processing one level at a time.
+	pending := make(map[string]int, len(nodes))
+	for _, n := range nodes {
+		for _, dep := range graph.dependencies[n.Path] {
+			if inSelection[dep.Path] {
+				pending[n.Path]++
+			}
+		}
+	}
+
+	var level []*HelmChart
+	for _, n := range nodes {
+		if pending[n.Path] == 0 {
+			level = append(level, n)
+		}
+	}
+
+	var (
+		levels  [][]*HelmChart
+		ordered int
+	)
+	for len(level) > 0 {
+		levels = append(levels, sortChartsAlphabetically(level))
+		ordered += len(level)
+
+		var next []*HelmChart
+		for _, n := range level {
+			for _, dependent := range graph.dependents[n.Path] {
+				if !inSelection[dependent.Path] {
+					continue
+				}
+				pending[dependent.Path]--
+				if pending[dependent.Path] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		level = next
+	}
+
+	if ordered < len(nodes) {
+		cycle := findCycle(graph, pending)
+		for i, p := range cycle {
+			if relPath, err := filepath.Rel(folder, p); err == nil {
+				cycle[i] = relPath
+			}
+		}
+		return nil, warnings, &DependencyCycleError{Cycle: cycle}
+	}
+
+	if isUseRelativePath {
+		for i, l := range levels {
+			for j, c := range l {
+				relPath, err := filepath.Rel(folder, c.Path)
+				if err != nil {
+					return nil, nil, err
+				}
+				rel := *c
+				rel.Path = relPath
+				levels[i][j] = &rel
+			}
+		}
+	}
+
+	return levels, warnings, nil
+}
+
+// findCycle returns a cycle among the charts that could not be ordered, i.e. still have pending dependencies.
+func findCycle(graph *dependencyGraph, pending map[string]int) []string {
+	var remaining []string
+	for p, n := range pending {
+		if n > 0 {
+			remaining = append(remaining, p)
+		}
+	}
+	sort.Strings(remaining)
+	if len(remaining) == 0 {
+		return nil
+	}
+
+	// Every remaining chart has a remaining dependency, so following them must end in a cycle.
+	visitedAt := make(map[string]int)
+	var path []string
+	current := remaining[0]
+	for {
+		if i, ok := visitedAt[current]; ok {
+			return append(path[i:], current)
+		}
+		visitedAt[current] = len(path)
+		path = append(path, current)
+
+		next := ""
+		for _, dep := range graph.dependencies[current] {
+			if pending[dep.Path] > 0 {
+				next = dep.Path
+				break
+			}
+		}
+		if next == "" {
+			return path
+		}
+		current = next
+	}
+}
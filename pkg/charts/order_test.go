@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestChart writes an apiVersion v2 chart below the directory depending on the given charts next to it.
+func writeTestChart(t *testing.T, dir, name string, dependencies ...string) {
+	t.Helper()
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: v2\nname: %s\nversion: 1.0.0\n", filepath.Base(name))
+	if len(dependencies) > 0 {
+		b.WriteString("dependencies:\n")
+	}
+	for _, d := range dependencies {
+		fmt.Fprintf(&b, "- name: %s\n  version: 1.0.0\n  repository: file://../%s\n", d, d)
+	}
+
+	chartPath := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(chartPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(chartPath, chartMetadataName), []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func chartPathsByLevel(levels [][]*HelmChart) [][]string {
+	res := make([][]string, 0, len(levels))
+	for _, l := range levels {
+		paths := make([]string, 0, len(l))
+		for _, c := range l {
+			paths = append(paths, filepath.ToSlash(c.Path))
+		}
+		res = append(res, paths)
+	}
+	return res
+}
+
+func TestOrderHelmChartsInFolder(t *testing.T) {
+	dir := t.TempDir()
+	// A diamond: app depends on frontend and backend, which both depend on base.
+	writeTestChart(t, dir, "base")
+	writeTestChart(t, dir, "frontend", "base")
+	writeTestChart(t, dir, "backend", "base")
+	writeTestChart(t, dir, "app", "frontend", "backend")
+	writeTestChart(t, dir, "standalone")
+
+	levels, warnings, err := OrderHelmChartsInFolder(t.Context(), dir, nil, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+
+	expected := [][]string{
+		{"base", "standalone"},
+		{"backend", "frontend"},
+		{"app"},
+	}
+	if actual := chartPathsByLevel(levels); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected levels %q, got %q", expected, actual)
+	}
+}
+
+func TestOrderHelmChartsInFolderSelected(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "base")
+	writeTestChart(t, dir, "middle", "base")
+	writeTestChart(t, dir, "app", "middle")
+
+	// Dependencies on charts that are not selected are ignored.
+	selected := []*HelmChart{{Path: "app"}, {Path: filepath.Join(dir, "middle")}}
+	levels, _, err := OrderHelmChartsInFolder(t.Context(), dir, selected, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{{"middle"}, {"app"}}
+	if actual := chartPathsByLevel(levels); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected levels %q, got %q", expected, actual)
+	}
+}
+
+func TestOrderHelmChartsInFolderCycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		charts map[string][]string
+		cycle  []string
+	}{
+		{
+			name:   "two charts",
+			charts: map[string][]string{"a": {"b"}, "b": {"a"}, "base": nil},
+			cycle:  []string{"a", "b", "a"},
+		},
+		{
+			name:   "three charts",
+			charts: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"a"}},
+			cycle:  []string{"a", "b", "c", "a"},
+		},
+		{
+			name: "chart depending on a cycle",
+			// The search starts at a, which is not part of the cycle itself.
+			charts: map[string][]string{"a": {"b"}, "b": {"c"}, "c": {"b"}},
+			cycle:  []string{"b", "c", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, deps := range tt.charts {
+			writeTestChart(t, dir, name, deps...)
+		}
+
+		_, _, err := OrderHelmChartsInFolder(t.Context(), dir, nil, nil, false)
+		var cycleErr *DependencyCycleError
+		if !errors.As(err, &cycleErr) {
+			t.Errorf("%s: expected a dependency cycle error, got %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(cycleErr.Cycle, tt.cycle) {
+			t.Errorf("%s: expected cycle %q, got %q", tt.name, tt.cycle, cycleErr.Cycle)
+		}
+		expected := "dependency cycle detected: " + strings.Join(tt.cycle, " -> ")
+		if err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %q", tt.name, expected, err.Error())
+		}
+	}
+}
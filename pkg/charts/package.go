@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/ignore"
+	helm_chart "k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/repo"
+)
+
+const indexFileName = "index.yaml"
+
+// PackageOptions configure PackageHelmCharts.
+type PackageOptions struct {
+	// Destination is the directory the archives and the index.yaml are written to.
+	Destination string
+	// BaseURL is used for the URLs of the archives in the index.yaml.
+	BaseURL string
+	// MergeIndex is an existing index.yaml merged into the generated one.
+	// Defaults to the index.yaml in the destination if it exists.
+	MergeIndex string
+}
+
+// PackagedChart describes the archive of a chart.
+type PackagedChart struct {
+	Chart *HelmChart
+	// Archive is the absolute path of the .tgz file.
+	Archive string
+	Digest  string
+}
+
+// PackageHelmCharts writes versioned .tgz archives of the given charts and an index.yaml into the destination.
+// Relative chart paths are resolved against the root directory. Files matching the .helmignore of a chart are skipped.
+func PackageHelmCharts(rootDirectory string, charts []*HelmChart, opts PackageOptions) ([]*PackagedChart, error) {
+	destination, err := filepath.Abs(opts.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return nil, err
+	}
+
+	mergeIndex := opts.MergeIndex
+	if mergeIndex == "" {
+		mergeIndex = filepath.Join(destination, indexFileName)
+	}
+	index, err := loadChartIndex(mergeIndex)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && opts.MergeIndex == "":
+		index = newChartIndex()
+	case err != nil:
+		return nil, fmt.Errorf("failed to load index %s: %w", mergeIndex, err)
+	}
+
+	res := make([]*PackagedChart, 0, len(charts))
+	for _, c := range charts {
+		chartPath := c.Path
+		if !filepath.IsAbs(chartPath) {
+			chartPath = filepath.Join(rootDirectory, chartPath)
+		}
+
+		meta, err := chartutil.LoadChartfile(filepath.Join(chartPath, chartMetadataName))
+		if err != nil {
+			return nil, err
+		}
+
+		archive := filepath.Join(destination, fmt.Sprintf("%s-%s.tgz", meta.GetName(), meta.GetVersion()))
+		p, err := packageHelmChart(chartPath, c, archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to package chart %s: %w", c.Name, err)
+		}
+
+		// The Helm 2 metadata lacks the fields introduced with apiVersion v2, so take them from the chart.
+		chart, err := loadChartMetadata(chartPath)
+		if err != nil {
+			return nil, err
+		}
+		index.add(meta, chart, filepath.Base(p.Archive), opts.BaseURL, p.Digest)
+
+		res = append(res, p)
+	}
+
+	if err := index.writeFile(filepath.Join(destination, indexFileName)); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func packageHelmChart(absPathChartFolder string, chart *HelmChart, archive string) (*PackagedChart, error) {
+	if err := writeChartArchive(absPathChartFolder, chart.Name, archive); err != nil {
+		return nil, err
+	}
+
+	digest, err := provenance.DigestFile(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PackagedChart{
+		Chart:   chart,
+		Archive: archive,
+		Digest:  digest,
+	}, nil
+}
+
+// writeChartArchive writes the chart folder into a gzipped tarball below a top-level directory named like the chart.
+func writeChartArchive(absPathChartFolder, name, archive string) (err error) {
+	rules, err := loadHelmIgnore(absPathChartFolder)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(archive)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+
+	err = filepath.Walk(absPathChartFolder, func(absPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(absPathChartFolder, absPath)
+		if err != nil || relPath == "." {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if rules.Ignore(relPath, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return addFileToArchive(tw, absPath, path.Join(name, relPath), info)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func addFileToArchive(tw *tar.Writer, absPath, name string, info os.FileInfo) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func loadHelmIgnore(absPathChartFolder string) (*ignore.Rules, error) {
+	rules := ignore.Empty()
+	r, err := ignore.ParseFile(filepath.Join(absPathChartFolder, ignore.HelmIgnore))
+	switch {
+	case err == nil:
+		rules = r
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	rules.AddDefaults()
+	return rules, nil
+}
+
+// chartIndex is an index.yaml. Helm 2 does not know the type and dependencies of apiVersion v2 charts,
+// so they are kept next to the entries and added when writing the index.
+type chartIndex struct {
+	*repo.IndexFile
+	fields map[indexKey]indexChartFields
+}
+
+type indexKey struct {
+	name, version string
+}
+
+// indexChartFields are the fields of an index entry missing in the Helm 2 chart metadata.
+type indexChartFields struct {
+	Name         string                  `json:"name"`
+	Version      string                  `json:"version"`
+	Type         string                  `json:"type,omitempty"`
+	Dependencies []*chartutil.Dependency `json:"dependencies,omitempty"`
+}
+
+func newChartIndex() *chartIndex {
+	return &chartIndex{
+		IndexFile: repo.NewIndexFile(),
+		fields:    make(map[indexKey]indexChartFields),
+	}
+}
+
+func loadChartIndex(fileName string) (*chartIndex, error) {
+	index, err := repo.LoadIndexFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var entries struct {
+		Entries map[string][]indexChartFields `json:"entries"`
+	}
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	res := &chartIndex{IndexFile: index, fields: make(map[indexKey]indexChartFields)}
+	for _, versions := range entries.Entries {
+		for _, v := range versions {
+			res.fields[indexKey{v.Name, v.Version}] = v
+		}
+	}
+	return res, nil
+}
+
+// add replaces the entry of the given chart version.
+func (i *chartIndex) add(meta *helm_chart.Metadata, chart *HelmChart, fileName, baseURL, digest string) {
+	removeIndexEntry(i.IndexFile, meta.GetName(), meta.GetVersion())
+	i.Add(meta, fileName, baseURL, digest)
+	i.fields[indexKey{meta.GetName(), meta.GetVersion()}] = indexChartFields{
+		Name:         meta.GetName(),
+		Version:      meta.GetVersion(),
+		Type:         chart.Type,
+		Dependencies: chart.Dependencies,
+	}
+}
+
+// indexFile is the serialized form of a chartIndex.
+type indexFile struct {
+	APIVersion string                          `json:"apiVersion"`
+	Generated  time.Time                       `json:"generated"`
+	Entries    map[string][]*indexChartVersion `json:"entries"`
+	PublicKeys []string                        `json:"publicKeys,omitempty"`
+}
+
+type indexChartVersion struct {
+	*repo.ChartVersion
+	Type         string                  `json:"type,omitempty"`
+	Dependencies []*chartutil.Dependency `json:"dependencies,omitempty"`
+}
+
+func (i *chartIndex) writeFile(fileName string) error {
+	i.SortEntries()
+	f := indexFile{
+		APIVersion: i.APIVersion,
+		Generated:  i.Generated,
+		Entries:    make(map[string][]*indexChartVersion, len(i.Entries)),
+		PublicKeys: i.PublicKeys,
+	}
+	for name, versions := range i.Entries {
+		entries := make([]*indexChartVersion, 0, len(versions))
+		for _, v := range versions {
+			fields := i.fields[indexKey{v.GetName(), v.GetVersion()}]
+			entries = append(entries, &indexChartVersion{
+				ChartVersion: v,
+				Type:         fields.Type,
+				Dependencies: fields.Dependencies,
+			})
+		}
+		f.Entries[name] = entries
+	}
+
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, data, 0o644)
+}
+
+// removeIndexEntry removes the given chart version from the index, so that it can be replaced by a newly packaged one.
+func removeIndexEntry(index *repo.IndexFile, name, version string) {
+	versions := index.Entries[name]
+	for i, v := range versions {
+		if v.Version == version {
+			index.Entries[name] = append(versions[:i], versions[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+const testExistingIndexYAML = `apiVersion: v1
+generated: "2025-01-01T00:00:00Z"
+entries:
+  lib:
+  - apiVersion: v2
+    name: lib
+    version: 0.1.0
+    type: library
+    dependencies:
+    - name: common
+      version: 1.0.0
+      repository: https://charts.example.com
+    urls:
+    - https://charts.example.com/lib-0.1.0.tgz
+    digest: abc
+  app:
+  - apiVersion: v2
+    name: app
+    version: 1.0.0
+    type: library
+    urls:
+    - https://charts.example.com/app-1.0.0.tgz
+    digest: outdated
+  - apiVersion: v2
+    name: app
+    version: 0.9.0
+    urls:
+    - https://charts.example.com/app-0.9.0.tgz
+    digest: def
+`
+
+type testIndexEntry struct {
+	Version      string   `json:"version"`
+	Type         string   `json:"type"`
+	URLs         []string `json:"urls"`
+	Digest       string   `json:"digest"`
+	Dependencies []struct {
+		Name       string `json:"name"`
+		Repository string `json:"repository"`
+	} `json:"dependencies"`
+}
+
+func TestPackageHelmChartsMergeIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "app", "base")
+	writeTestChart(t, dir, "base")
+	writeTestFiles(t, dir, map[string]string{
+		"app/values.yaml":       "replicas: 1\n",
+		"existing/index.yaml":   testExistingIndexYAML,
+		"app/templates/cm.yaml": "kind: ConfigMap\n",
+	})
+
+	destination := filepath.Join(dir, "dist")
+	packaged, err := PackageHelmCharts(dir, []*HelmChart{{Name: "app", Path: "app"}}, PackageOptions{
+		Destination: destination,
+		BaseURL:     "https://charts.example.com",
+		MergeIndex:  filepath.Join(dir, "existing/index.yaml"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packaged) != 1 || packaged[0].Archive != filepath.Join(destination, "app-1.0.0.tgz") {
+		t.Fatalf("expected the archive app-1.0.0.tgz, got %v", packaged)
+	}
+	if _, err := os.Stat(packaged[0].Archive); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(destination, indexFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index struct {
+		Entries map[string][]testIndexEntry `json:"entries"`
+	}
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		t.Fatal(err)
+	}
+
+	// The entries of the existing index keep the fields unknown to Helm 2.
+	lib := index.Entries["lib"]
+	if len(lib) != 1 || lib[0].Type != ChartTypeLibrary || len(lib[0].Dependencies) != 1 || lib[0].Dependencies[0].Name != "common" {
+		t.Errorf("expected the library entry with its dependency to be kept, got %+v", lib)
+	}
+
+	// The packaged version replaces the existing entry of the same version.
+	app := index.Entries["app"]
+	versions := make([]string, 0, len(app))
+	for _, v := range app {
+		versions = append(versions, v.Version)
+	}
+	if expected := []string{"1.0.0", "0.9.0"}; !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("expected the versions %q of app, got %q", expected, versions)
+	}
+	packagedEntry := app[0]
+	if packagedEntry.Type != ChartTypeApplication {
+		t.Errorf("expected the type %q of the packaged chart, got %q", ChartTypeApplication, packagedEntry.Type)
+	}
+	if packagedEntry.Digest != packaged[0].Digest {
+		t.Errorf("expected the digest %q of the archive, got %q", packaged[0].Digest, packagedEntry.Digest)
+	}
+	if expected := []string{"https://charts.example.com/app-1.0.0.tgz"}; !reflect.DeepEqual(packagedEntry.URLs, expected) {
+		t.Errorf("expected the URLs %q, got %q", expected, packagedEntry.URLs)
+	}
+	if len(packagedEntry.Dependencies) != 1 || packagedEntry.Dependencies[0].Repository != "file://../base" {
+		t.Errorf("expected the local dependency of the packaged chart, got %+v", packagedEntry.Dependencies)
+	}
+}
+
+func TestPackageHelmChartsMissingMergeIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "app")
+
+	_, err := PackageHelmCharts(dir, []*HelmChart{{Name: "app", Path: "app"}}, PackageOptions{
+		Destination: filepath.Join(dir, "dist"),
+		MergeIndex:  filepath.Join(dir, "missing.yaml"),
+	})
+	if err == nil {
+		t.Error("expected an error for the missing index to merge")
+	}
+}
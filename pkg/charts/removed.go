@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// RemovedHelmChart is a chart that existed at the merge base but no longer exists at its path at the given commit.
+type RemovedHelmChart struct {
+	// Chart contains the metadata of the chart at the merge base without dependencies. The path is the old path.
+	Chart *HelmChart
+	// MovedTo is the new path of the chart if it was moved. It is empty if the chart was removed.
+	MovedTo string
+}
+
+// IsMoved returns true if the chart still exists at another path.
+func (r *RemovedHelmChart) IsMoved() bool {
+	return r.MovedTo != ""
+}
+
+// ListRemovedHelmChartsInFolderWithGitOptions lists the Helm charts that were removed or moved compared to the given remote/branch:commit.
+// The charts are detected by reading the Chart.yaml of deleted and renamed files at the merge base.
+// Warnings are printed to stderr. See ListChangedCharts for a variant that returns them and can be cancelled.
+func ListRemovedHelmChartsInFolderWithGitOptions(opts GitOptions, rootDirectory string, excludeDirs []string, remote, branch, commit string, isUseRelativePath bool) ([]*RemovedHelmChart, error) {
+	changed, err := listChangedHelmCharts(context.Background(), opts, rootDirectory, excludeDirs, remote, branch, commit, true)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(changed.warnings)
+
+	if isUseRelativePath {
+		if err := relativeRemovedHelmCharts(rootDirectory, changed.removed); err != nil {
+			return nil, err
+		}
+	}
+	return changed.removed, nil
+}
+
+// relativeRemovedHelmCharts makes the old and new paths of the given charts relative to the root directory.
+func relativeRemovedHelmCharts(rootDirectory string, removed []*RemovedHelmChart) error {
+	for _, r := range removed {
+		relPath, err := filepath.Rel(rootDirectory, r.Chart.Path)
+		if err != nil {
+			return err
+		}
+		r.Chart.Path = relPath
+
+		if r.MovedTo != "" {
+			relPath, err := filepath.Rel(rootDirectory, r.MovedTo)
+			if err != nil {
+				return err
+			}
+			r.MovedTo = relPath
+		}
+	}
+	return nil
+}
+
+// findRemovedHelmCharts looks up the chart directories of deleted and renamed files at the base revision
+// and returns the charts whose Chart.yaml does not exist at the target anymore.
+// The changed charts are used to detect a move if the rename of the Chart.yaml itself was not detected.
+// Charts whose metadata at the base cannot be loaded are reported as warnings.
+func findRemovedHelmCharts(ctx context.Context, git gitBackend, rootDirectory string, rules *excludeRules, base, target string, changedFiles []*ChangedFile, changed []*HelmChart) ([]*RemovedHelmChart, []Warning, error) {
+	isBaseChart := make(map[string]bool)
+	var baseChartPaths []string
+	for _, f := range changedFiles {
+		var oldPath string
+		switch f.Status {
+		case FileDeleted:
+			oldPath = f.Path
+		case FileRenamed:
+			oldPath = f.OldPath
+		default:
+			continue
+		}
+
+		for dir := filepath.Dir(oldPath); isBelowDirectory(rootDirectory, dir); dir = filepath.Dir(dir) {
+			if isChart, ok := isBaseChart[dir]; ok {
+				if isChart {
+					break
+				}
+				continue
+			}
+
+			_, err := git.readFile(ctx, base, filepath.Join(dir, chartMetadataName))
+			switch {
+			case errors.Is(err, errFileNotFound):
+				isBaseChart[dir] = false
+				continue
+			case err != nil:
+				return nil, nil, err
+			}
+
+			isBaseChart[dir] = true
+			if !rules.isExcluded(dir) {
+				baseChartPaths = append(baseChartPaths, dir)
+			}
+			break
+		}
+	}
+
+	var (
+		res      []*RemovedHelmChart
+		warnings []Warning
+	)
+	for _, chartPath := range baseChartPaths {
+		chartFile := filepath.Join(chartPath, chartMetadataName)
+		_, err := git.readFile(ctx, target, chartFile)
+		if err == nil {
+			// Only some files of the chart were deleted.
+			continue
+		}
+		if !errors.Is(err, errFileNotFound) {
+			return nil, nil, err
+		}
+
+		c, err := loadChartMetadataAtRevision(ctx, git, base, chartPath)
+		if err != nil {
+			warnings = append(warnings, newChartMetadataWarning(chartPath, err))
+			continue
+		}
+		res = append(res, &RemovedHelmChart{
+			Chart:   c,
+			MovedTo: findMovedChart(c, changedFiles, changed),
+		})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Chart.Path < res[j].Chart.Path
+	})
+	return res, warnings, nil
+}
+
+// findMovedChart returns the new path of the given chart or an empty string if it was removed.
+func findMovedChart(removed *HelmChart, changedFiles []*ChangedFile, changed []*HelmChart) string {
+	chartFile := filepath.Join(removed.Path, chartMetadataName)
+	for _, f := range changedFiles {
+		if f.Status == FileRenamed && f.OldPath == chartFile {
+			return filepath.Dir(f.Path)
+		}
+	}
+
+	// The rename is not detected if the Chart.yaml was modified too much, e.g. when the chart was renamed.
+	// Fall back to a newly added chart with the same name.
+	for _, c := range changed {
+		if c.Name != removed.Name {
+			continue
+		}
+		for _, f := range c.Files {
+			if f.Path == chartMetadataName && f.Status == FileAdded {
+				return c.Path
+			}
+		}
+	}
+	return ""
+}
+
+func loadChartMetadataAtRevision(ctx context.Context, git gitBackend, rev, absPathChartFolder string) (*HelmChart, error) {
+	data, err := git.readFile(ctx, rev, filepath.Join(absPathChartFolder, chartMetadataName))
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := parseChartMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", absPathChartFolder, rev, err)
+	}
+
+	version, err := semver.NewVersion(meta.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", absPathChartFolder, rev, err)
+	}
+
+	return &HelmChart{
+		Name:        meta.Name,
+		Version:     version,
+		Path:        absPathChartFolder,
+		APIVersion:  meta.APIVersion,
+		Type:        meta.Type,
+		KubeVersion: meta.KubeVersion,
+	}, nil
+}
+
+func isBelowDirectory(directory, absPath string) bool {
+	return strings.HasPrefix(absPath, directory+string(filepath.Separator))
+}
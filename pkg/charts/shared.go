@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SharedFileMapping maps files outside of chart directories, e.g. global values or region overlays, to the charts using them.
+//
+//	sharedFiles:
+//	- paths: [global/values/*.yaml, regions/]
+//	  charts: [keystone, "openstack/*"]
+type SharedFileMapping struct {
+	// Paths are gitignore-style patterns relative to the root directory, see .helmchartsignore.
+	Paths []string `json:"paths"`
+	// Charts are chart names or, if they contain a slash, patterns of chart paths relative to the root directory.
+	// "*", "?" and "[...]" are supported in both and "**" in chart paths.
+	Charts []string `json:"charts"`
+}
+
+// addChartsAffectedBySharedFiles adds the charts mapped to the changed shared files to the changed charts.
+// The shared files are added to the changed files of the charts with paths relative to each chart.
+func addChartsAffectedBySharedFiles(ctx context.Context, rootDirectory string, excludeDirs []string, mappings []SharedFileMapping, sharedFiles []*ChangedFile, changed []*HelmChart) ([]*HelmChart, []Warning, error) {
+	allCharts, warnings, err := listHelmCharts(ctx, rootDirectory, excludeDirs, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	added := make(map[[2]string]bool)
+	byPath := make(map[string]*HelmChart, len(changed))
+	for _, c := range changed {
+		byPath[c.Path] = c
+	}
+
+	for _, m := range mappings {
+		rules, err := newPathRules(rootDirectory, m.Paths)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, f := range sharedFiles {
+			if !rules.isExcluded(f.Path) && (f.OldPath == "" || !rules.isExcluded(f.OldPath)) {
+				continue
+			}
+
+			for _, c := range allCharts {
+				ok, err := matchesChartPattern(rootDirectory, c, m.Charts)
+				if err != nil {
+					return nil, nil, err
+				}
+				if !ok {
+					continue
+				}
+
+				existing, found := byPath[c.Path]
+				if !found {
+					cp := *c
+					existing = &cp
+					existing.Reason = ChangeReasonSharedFile
+					if relPath, err := filepath.Rel(rootDirectory, f.Path); err == nil {
+						existing.Via = relPath
+					}
+					byPath[c.Path] = existing
+					changed = append(changed, existing)
+				}
+				if key := [2]string{c.Path, f.Path}; !added[key] {
+					added[key] = true
+					existing.Files = append(existing.Files, relativeChangedFile(existing.Path, f))
+				}
+			}
+		}
+	}
+
+	return sortChartsAlphabetically(changed), warnings, nil
+}
+
+func matchesChartPattern(rootDirectory string, chart *HelmChart, patterns []string) (bool, error) {
+	for _, p := range patterns {
+		if !strings.Contains(p, "/") {
+			ok, err := path.Match(p, chart.Name)
+			if err != nil {
+				return false, fmt.Errorf("invalid chart pattern %q: %w", p, err)
+			}
+			if ok {
+				return true, nil
+			}
+			continue
+		}
+
+		pattern, err := parseExcludePattern(p)
+		if err != nil {
+			return false, err
+		}
+		relPath, err := filepath.Rel(rootDirectory, chart.Path)
+		if err != nil {
+			return false, err
+		}
+		if pattern != nil && matchSegments(pattern.segments, strings.Split(filepath.ToSlash(relPath), "/")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// describeChangedCharts summarizes the changed charts as "<path> <reason> <via>: <files>" with paths relative to the directory.
+func describeChangedCharts(dir string, charts []*HelmChart) []string {
+	res := make([]string, 0, len(charts))
+	for _, c := range charts {
+		chartPath := c.Path
+		if relPath, err := filepath.Rel(dir, c.Path); err == nil {
+			chartPath = relPath
+		}
+		files := make([]string, 0, len(c.Files))
+		for _, f := range c.Files {
+			files = append(files, fmt.Sprintf("%s %s", f.Status, filepath.ToSlash(f.Path)))
+		}
+		res = append(res, fmt.Sprintf("%s %s %s: %s", filepath.ToSlash(chartPath), c.Reason, filepath.ToSlash(c.Via), strings.Join(files, ", ")))
+	}
+	return res
+}
+
+func TestAddChartsAffectedBySharedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "openstack/keystone")
+	writeTestChart(t, dir, "openstack/nova")
+	writeTestChart(t, dir, "system/keystone")
+	writeTestChart(t, dir, "system/kube-proxy")
+
+	mappings := []SharedFileMapping{
+		// Chart names match charts in any directory.
+		{Paths: []string{"global/values/*.yaml"}, Charts: []string{"keystone"}},
+		// Chart paths are anchored to the root directory.
+		{Paths: []string{"regions/"}, Charts: []string{"openstack/*"}},
+	}
+	sharedFiles := []*ChangedFile{
+		{Status: FileModified, Path: filepath.Join(dir, "global/values/keystone.yaml")},
+		{Status: FileAdded, Path: filepath.Join(dir, "regions/eu/values.yaml")},
+		{Status: FileModified, Path: filepath.Join(dir, "docs/README.md")},
+	}
+	changed := []*HelmChart{{
+		Name:   "nova",
+		Path:   filepath.Join(dir, "openstack/nova"),
+		Reason: ChangeReasonChanged,
+		Files:  []*ChangedFile{{Status: FileModified, Path: "values.yaml"}},
+	}}
+
+	res, warnings, err := addChartsAffectedBySharedFiles(t.Context(), dir, nil, mappings, sharedFiles, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) > 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+
+	expected := []string{
+		"openstack/keystone shared-file global/values/keystone.yaml: modified ../../global/values/keystone.yaml, added ../../regions/eu/values.yaml",
+		"system/keystone shared-file global/values/keystone.yaml: modified ../../global/values/keystone.yaml",
+		// The reason of changed charts is kept.
+		"openstack/nova changed : modified values.yaml, added ../../regions/eu/values.yaml",
+	}
+	if actual := describeChangedCharts(dir, res); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changed charts\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestAddChartsAffectedBySharedFilesRenamed(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "keystone")
+
+	// A file moved out of a shared directory still affects the charts using it.
+	mappings := []SharedFileMapping{{Paths: []string{"/global"}, Charts: []string{"keystone"}}}
+	sharedFiles := []*ChangedFile{{
+		Status:  FileRenamed,
+		Path:    filepath.Join(dir, "archive/values.yaml"),
+		OldPath: filepath.Join(dir, "global/values.yaml"),
+	}}
+
+	res, _, err := addChartsAffectedBySharedFiles(t.Context(), dir, nil, mappings, sharedFiles, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"keystone shared-file archive/values.yaml: renamed ../archive/values.yaml"}
+	if actual := describeChangedCharts(dir, res); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changed charts %q, got %q", expected, actual)
+	}
+}
+
+func TestAddChartsAffectedBySharedFilesInvalidPattern(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "keystone")
+
+	mappings := []SharedFileMapping{{Paths: []string{"global"}, Charts: []string{"[keystone"}}}
+	sharedFiles := []*ChangedFile{{Status: FileModified, Path: filepath.Join(dir, "global/values.yaml")}}
+
+	_, _, err := addChartsAffectedBySharedFiles(t.Context(), dir, nil, mappings, sharedFiles, nil)
+	if err == nil || !strings.Contains(err.Error(), `invalid chart pattern "[keystone"`) {
+		t.Errorf("expected an invalid chart pattern error, got %v", err)
+	}
+}
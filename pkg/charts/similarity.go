@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"hash/fnv"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SimilarCharts is a pair of charts with similar contents.
+type SimilarCharts struct {
+	Charts [2]*HelmChart
+	// Score is the similarity of the contents between 0 (nothing in common) and 1 (identical).
+	Score float64
+	// Identical is true if all templates and values files have the same content.
+	Identical bool
+}
+
+// chartFingerprint summarizes the templates and values files of a chart.
+// The name of the chart is replaced by a placeholder, so that renamed copies are still recognized.
+type chartFingerprint struct {
+	chart *HelmChart
+	// files maps the path relative to the chart to the hash of the normalized content.
+	files map[string][sha256.Size]byte
+	// lines counts the hashes of the normalized, non-empty lines of all files.
+	lines map[uint64]int
+}
+
+// FindSimilarChartsInFolder finds charts in the given folder whose templates and values files are identical or
+// similar regardless of their names. Only pairs with a score of at least minScore are returned, the most similar first.
+//
+// Lines occurring in many charts, e.g. "{{- end }}", contribute less to the score than lines unique to few charts.
+func FindSimilarChartsInFolder(folder string, excludeDirs []string, minScore float64, isUseRelativePath bool) ([]*SimilarCharts, error) {
+	folder, err := filepath.Abs(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	foundCharts, err := ListHelmChartsInFolder(folder, excludeDirs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprints := make([]*chartFingerprint, 0, len(foundCharts))
+	documentFrequency := make(map[uint64]int)
+	for _, c := range foundCharts {
+		fp, err := newChartFingerprint(c)
+		if err != nil {
+			return nil, err
+		}
+		for line := range fp.lines {
+			documentFrequency[line]++
+		}
+		fingerprints = append(fingerprints, fp)
+	}
+
+	weight := func(line uint64) float64 {
+		return math.Log(1 + float64(len(fingerprints))/float64(documentFrequency[line]))
+	}
+
+	var res []*SimilarCharts
+	for i, a := range fingerprints {
+		for _, b := range fingerprints[i+1:] {
+			s := &SimilarCharts{
+				Charts:    [2]*HelmChart{a.chart, b.chart},
+				Identical: a.isIdentical(b),
+			}
+			if s.Identical {
+				s.Score = 1
+			} else {
+				s.Score = a.similarity(b, weight)
+			}
+			if len(a.files) > 0 && s.Score >= minScore {
+				res = append(res, s)
+			}
+		}
+	}
+
+	if isUseRelativePath {
+		for _, s := range res {
+			for i, c := range s.Charts {
+				relPath, err := filepath.Rel(folder, c.Path)
+				if err != nil {
+					return nil, err
+				}
+				rel := *c
+				rel.Path = relPath
+				s.Charts[i] = &rel
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		if res[i].Charts[0].Path != res[j].Charts[0].Path {
+			return res[i].Charts[0].Path < res[j].Charts[0].Path
+		}
+		return res[i].Charts[1].Path < res[j].Charts[1].Path
+	})
+	return res, nil
+}
+
+func newChartFingerprint(chart *HelmChart) (*chartFingerprint, error) {
+	fp := &chartFingerprint{
+		chart: chart,
+		files: make(map[string][sha256.Size]byte),
+		lines: make(map[uint64]int),
+	}
+
+	err := filepath.WalkDir(chart.Path, func(absPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(chart.Path, absPath)
+		if err != nil || relPath == "." {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if d.IsDir() {
+			// Vendored and nested charts are fingerprinted on their own.
+			if relPath == "charts" || isChartDirectory(absPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || !isFingerprintedFile(relPath) {
+			return nil
+		}
+
+		return fp.addFile(absPath, relPath)
+	})
+	return fp, err
+}
+
+// isFingerprintedFile returns true for templates, helpers and values files.
+func isFingerprintedFile(relPath string) bool {
+	if strings.HasPrefix(relPath, "templates/") {
+		return true
+	}
+	base := filepath.Base(relPath)
+	return relPath == base && strings.HasPrefix(base, "values") && (strings.HasSuffix(base, ".yaml") || strings.HasSuffix(base, ".yml") || strings.HasSuffix(base, ".json"))
+}
+
+func isChartDirectory(absPath string) bool {
+	_, err := os.Stat(filepath.Join(absPath, chartMetadataName))
+	return err == nil
+}
+
+func (fp *chartFingerprint) addFile(absPath, relPath string) error {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	content := sha256.New()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := fp.normalizeLine(scanner.Text())
+		content.Write([]byte(line + "\n"))
+		if line == "" {
+			continue
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(line))
+		fp.lines[h.Sum64()]++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], content.Sum(nil))
+	fp.files[relPath] = sum
+	return nil
+}
+
+func (fp *chartFingerprint) normalizeLine(line string) string {
+	line = strings.TrimSpace(line)
+	if name := fp.chart.Name; len(name) >= 3 {
+		line = strings.ReplaceAll(line, name, "<chart>")
+	}
+	return line
+}
+
+func (fp *chartFingerprint) isIdentical(other *chartFingerprint) bool {
+	if len(fp.files) != len(other.files) {
+		return false
+	}
+	for relPath, sum := range fp.files {
+		if otherSum, ok := other.files[relPath]; !ok || otherSum != sum {
+			return false
+		}
+	}
+	return true
+}
+
+// similarity is the weighted Jaccard index of the lines of both charts.
+func (fp *chartFingerprint) similarity(other *chartFingerprint, weight func(uint64) float64) float64 {
+	var intersection, union float64
+	for line, count := range fp.lines {
+		otherCount := other.lines[line]
+		w := weight(line)
+		intersection += w * float64(min(count, otherCount))
+		union += w * float64(max(count, otherCount))
+	}
+	for line, otherCount := range other.lines {
+		if _, ok := fp.lines[line]; !ok {
+			union += weight(line) * float64(otherCount)
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+	return intersection / union
+}
@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		absPath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(absPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(absPath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChartFingerprintSimilarity(t *testing.T) {
+	unit := func(uint64) float64 { return 1 }
+
+	tests := []struct {
+		name     string
+		a, b     map[string]string
+		expected float64
+	}{
+		{
+			name:     "identical",
+			a:        map[string]string{"templates/cm.yaml": "a\nb\n", "values.yaml": "c\n"},
+			b:        map[string]string{"templates/cm.yaml": "a\nb\n", "values.yaml": "c\n"},
+			expected: 1,
+		},
+		{
+			name:     "disjoint",
+			a:        map[string]string{"templates/cm.yaml": "a\nb\n"},
+			b:        map[string]string{"templates/cm.yaml": "c\nd\n"},
+			expected: 0,
+		},
+		{
+			name: "partially overlapping templates and values",
+			// Lines are compared regardless of the file and the indentation, empty lines are ignored.
+			a:        map[string]string{"templates/cm.yaml": "a\n\n  b\n", "values.yaml": "c\n"},
+			b:        map[string]string{"templates/secret.yaml": "a\nb\n", "values.yaml": "d\n"},
+			expected: 2.0 / 4,
+		},
+		{
+			name:     "repeated lines",
+			a:        map[string]string{"templates/cm.yaml": "a\na\na\nb\n"},
+			b:        map[string]string{"templates/cm.yaml": "a\nb\n"},
+			expected: 2.0 / 4,
+		},
+		{
+			name:     "no lines",
+			a:        map[string]string{"values.yaml": "\n"},
+			b:        map[string]string{"values.yaml": ""},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeTestFiles(t, filepath.Join(dir, "a"), tt.a)
+		writeTestFiles(t, filepath.Join(dir, "b"), tt.b)
+		a, err := newChartFingerprint(&HelmChart{Name: "a", Path: filepath.Join(dir, "a")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := newChartFingerprint(&HelmChart{Name: "b", Path: filepath.Join(dir, "b")})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := a.similarity(b, unit); math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected similarity %.3f, got %.3f", tt.name, tt.expected, actual)
+		}
+		if actual := b.similarity(a, unit); math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("%s: expected symmetric similarity %.3f, got %.3f", tt.name, tt.expected, actual)
+		}
+	}
+}
+
+func TestChartFingerprintWeightedSimilarity(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, filepath.Join(dir, "a"), map[string]string{"templates/cm.yaml": "common\nrare\n"})
+	writeTestFiles(t, filepath.Join(dir, "b"), map[string]string{"templates/cm.yaml": "common\nother\n"})
+	a, err := newChartFingerprint(&HelmChart{Name: "a", Path: filepath.Join(dir, "a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newChartFingerprint(&HelmChart{Name: "b", Path: filepath.Join(dir, "b")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The shared line is weighted down like a line occurring in many charts.
+	weights := make(map[uint64]float64)
+	for line := range b.lines {
+		weights[line] = 3
+	}
+	for line := range a.lines {
+		if _, ok := weights[line]; ok {
+			weights[line] = 1
+		} else {
+			weights[line] = 3
+		}
+	}
+	actual := a.similarity(b, func(line uint64) float64 { return weights[line] })
+	if expected := 1.0 / 7; math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("expected similarity %.3f, got %.3f", expected, actual)
+	}
+}
+
+func TestFindSimilarChartsInFolder(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "alpha")
+	writeTestFiles(t, filepath.Join(dir, "alpha"), map[string]string{
+		"templates/cm.yaml": "name: alpha-config\nkey: value\n",
+		"values.yaml":       "replicas: 1\nimage: alpha\n",
+	})
+	// A renamed copy of alpha.
+	writeTestChart(t, dir, "bravo")
+	writeTestFiles(t, filepath.Join(dir, "bravo"), map[string]string{
+		"templates/cm.yaml": "name: bravo-config\nkey: value\n",
+		"values.yaml":       "replicas: 1\nimage: bravo\n",
+	})
+	// Shares some lines with alpha and bravo.
+	writeTestChart(t, dir, "charlie")
+	writeTestFiles(t, filepath.Join(dir, "charlie"), map[string]string{
+		"templates/cm.yaml": "name: charlie-config\nkey: other\n",
+		"values.yaml":       "replicas: 1\nimage: nginx\n",
+	})
+	// Nothing in common with the others.
+	writeTestChart(t, dir, "delta")
+	writeTestFiles(t, filepath.Join(dir, "delta"), map[string]string{
+		"templates/deployment.yaml": "kind: Deployment\nreplicas: {{ .Values.replicas }}\n",
+	})
+
+	res, err := FindSimilarChartsInFolder(dir, nil, 0, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores := make(map[[2]string]*SimilarCharts)
+	for _, s := range res {
+		scores[[2]string{s.Charts[0].Name, s.Charts[1].Name}] = s
+	}
+	if len(scores) != 6 {
+		t.Fatalf("expected all 6 pairs with a minimum similarity of 0, got %d", len(scores))
+	}
+
+	if s := scores[[2]string{"alpha", "bravo"}]; !s.Identical || s.Score != 1 {
+		t.Errorf("expected alpha and bravo to be identical, got score %.3f", s.Score)
+	}
+	if s := scores[[2]string{"alpha", "charlie"}]; s.Identical || s.Score <= 0 || s.Score >= 1 {
+		t.Errorf("expected alpha and charlie to be partially similar, got score %.3f", s.Score)
+	}
+	if s := scores[[2]string{"alpha", "delta"}]; s.Identical || s.Score != 0 {
+		t.Errorf("expected alpha and delta to have nothing in common, got score %.3f", s.Score)
+	}
+	if res[0].Charts[0].Name != "alpha" || res[0].Charts[1].Name != "bravo" {
+		t.Errorf("expected the identical charts first, got %s and %s", res[0].Charts[0].Name, res[0].Charts[1].Name)
+	}
+
+	// Only the pairs reaching the threshold are returned.
+	partial := scores[[2]string{"alpha", "charlie"}].Score
+	thresholds := []struct {
+		minScore float64
+		expected [][2]string
+	}{
+		{0.01, [][2]string{{"alpha", "bravo"}, {"alpha", "charlie"}, {"bravo", "charlie"}}},
+		{partial + 0.01, [][2]string{{"alpha", "bravo"}}},
+	}
+	for _, tt := range thresholds {
+		res, err := FindSimilarChartsInFolder(dir, nil, tt.minScore, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs := make([][2]string, 0, len(res))
+		for _, s := range res {
+			pairs = append(pairs, [2]string{s.Charts[0].Name, s.Charts[1].Name})
+		}
+		// Pairs with the same score might be ordered differently due to rounding.
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i][0]+"/"+pairs[i][1] < pairs[j][0]+"/"+pairs[j][1]
+		})
+		if !reflect.DeepEqual(pairs, tt.expected) {
+			t.Errorf("expected pairs %q with a minimum similarity of %.3f, got %q", tt.expected, tt.minScore, pairs)
+		}
+	}
+}
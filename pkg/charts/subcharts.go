@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"path/filepath"
+)
+
+// RollUpSubcharts replaces changed subcharts by the outermost deployable chart containing them in its charts/ directory,
+// which is the chart that gets deployed. Changed files are attributed to that chart with paths relative to it.
+// Subcharts are kept if all parent charts are library charts. Changes of vendored charts/*.tgz archives are already
+// reported for the parent chart. Parent charts whose metadata cannot be loaded are skipped and reported as warnings.
+func RollUpSubcharts(ctx context.Context, rootDirectory string, changed []*HelmChart, excludeDirs []string, isUseRelativePath bool) ([]*HelmChart, []Warning, error) {
+	rootDirectory, err := filepath.Abs(rootDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rules, err := newExcludeRules(rootDirectory, excludeDirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		res      []*HelmChart
+		warnings []Warning
+	)
+	byPath := make(map[string]*HelmChart)
+	for _, c := range changed {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		chartPath := c.Path
+		if !filepath.IsAbs(chartPath) {
+			chartPath = filepath.Join(rootDirectory, chartPath)
+		}
+
+		outermost, parentWarnings := findOutermostParentChart(rootDirectory, chartPath, rules)
+		warnings = appendWarnings(warnings, parentWarnings...)
+		targetPath := chartPath
+		if outermost != nil {
+			targetPath = outermost.Path
+		}
+
+		target, ok := byPath[targetPath]
+		if !ok {
+			if targetPath == chartPath {
+				// Do not modify the given chart.
+				cp := *c
+				cp.Path = chartPath
+				cp.Files = nil
+				target = &cp
+			} else {
+				target = outermost
+				target.Reason = ChangeReasonSubchart
+				target.Via = c.Name
+			}
+			byPath[targetPath] = target
+			res = append(res, target)
+		}
+
+		if targetPath == chartPath {
+			// Changes of the chart itself take precedence over changes of its subcharts.
+			target.Reason = c.Reason
+			target.Via = c.Via
+		}
+		for _, f := range c.Files {
+			target.Files = append(target.Files, rebaseChangedFile(chartPath, targetPath, f))
+		}
+	}
+
+	if isUseRelativePath {
+		for _, c := range res {
+			if !filepath.IsAbs(c.Path) {
+				continue
+			}
+			relPath, err := filepath.Rel(rootDirectory, c.Path)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.Path = relPath
+		}
+	}
+	return sortChartsAlphabetically(res), warnings, nil
+}
+
+// findOutermostParentChart returns the outermost non-library chart below the root directory containing the given chart
+// as subchart, i.e. via <parent>/charts/<subchart> with any number of levels, or nil if there is none.
+func findOutermostParentChart(rootDirectory, chartPath string, rules *excludeRules) (*HelmChart, []Warning) {
+	var (
+		outermost *HelmChart
+		warnings  []Warning
+	)
+	dir := chartPath
+	for {
+		chartsDir := filepath.Dir(dir)
+		if filepath.Base(chartsDir) != "charts" {
+			break
+		}
+		parent := filepath.Dir(chartsDir)
+		if !isBelowDirectory(rootDirectory, parent) || !isChartDirectory(parent) || rules.isExcluded(parent) {
+			break
+		}
+
+		c, err := loadChartMetadata(parent)
+		if err != nil {
+			warnings = append(warnings, newChartMetadataWarning(parent, err))
+		} else if !c.IsLibrary() {
+			outermost = c
+		}
+		dir = parent
+	}
+	return outermost, warnings
+}
+
+// rebaseChangedFile returns a copy of the changed file with paths relative to another chart.
+func rebaseChangedFile(chartPath, targetPath string, f *ChangedFile) *ChangedFile {
+	abs := *f
+	if !filepath.IsAbs(abs.Path) {
+		abs.Path = filepath.Join(chartPath, abs.Path)
+	}
+	if abs.OldPath != "" && !filepath.IsAbs(abs.OldPath) {
+		abs.OldPath = filepath.Join(chartPath, abs.OldPath)
+	}
+	return relativeChangedFile(targetPath, &abs)
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRollUpSubcharts(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "umbrella")
+	writeTestFiles(t, dir, map[string]string{
+		"umbrella/charts/mid/Chart.yaml": "apiVersion: v2\nname: mid\nversion: 1.0.0\ntype: library\n",
+		"lib/Chart.yaml":                 "apiVersion: v2\nname: lib\nversion: 1.0.0\ntype: library\n",
+		"broken/Chart.yaml":              "apiVersion: v2\nname: broken\nversion: not-a-version\n",
+	})
+	writeTestChart(t, dir, "umbrella/charts/mid/charts/leaf")
+	writeTestChart(t, dir, "lib/charts/sub")
+	writeTestChart(t, dir, "broken/charts/child")
+
+	changedChart := func(name string, files ...string) *HelmChart {
+		c := &HelmChart{
+			Name:   filepath.Base(name),
+			Path:   filepath.Join(dir, filepath.FromSlash(name)),
+			Reason: ChangeReasonChanged,
+		}
+		for _, f := range files {
+			c.Files = append(c.Files, &ChangedFile{Status: FileModified, Path: f})
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		changed  []*HelmChart
+		expected []string
+		warnings int
+	}{
+		{
+			name:    "subchart below a library chart",
+			changed: []*HelmChart{changedChart("umbrella/charts/mid/charts/leaf", "values.yaml")},
+			expected: []string{
+				"umbrella subchart leaf: modified charts/mid/charts/leaf/values.yaml",
+			},
+		},
+		{
+			name: "library subchart and changed parent",
+			changed: []*HelmChart{
+				changedChart("umbrella/charts/mid", "templates/_helpers.tpl"),
+				changedChart("umbrella/charts/mid/charts/leaf", "values.yaml"),
+				changedChart("umbrella", "Chart.yaml"),
+			},
+			// Changes of the chart itself take precedence.
+			expected: []string{
+				"umbrella changed : modified charts/mid/templates/_helpers.tpl, modified charts/mid/charts/leaf/values.yaml, modified Chart.yaml",
+			},
+		},
+		{
+			name:    "only library parents",
+			changed: []*HelmChart{changedChart("lib/charts/sub", "values.yaml")},
+			expected: []string{
+				"lib/charts/sub changed : modified values.yaml",
+			},
+		},
+		{
+			name:    "unloadable parent",
+			changed: []*HelmChart{changedChart("broken/charts/child", "values.yaml")},
+			expected: []string{
+				"broken/charts/child changed : modified values.yaml",
+			},
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		res, warnings, err := RollUpSubcharts(t.Context(), dir, tt.changed, nil, false)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		if actual := describeChangedCharts(dir, res); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%s: expected changed charts\n%s\ngot\n%s", tt.name, strings.Join(tt.expected, "\n"), strings.Join(actual, "\n"))
+		}
+		if len(warnings) != tt.warnings {
+			t.Errorf("%s: expected %d warnings, got %v", tt.name, tt.warnings, warnings)
+		}
+	}
+}
+
+func TestRollUpSubchartsExcludedParent(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, "umbrella")
+	writeTestChart(t, dir, "umbrella/charts/leaf")
+
+	changed := []*HelmChart{{
+		Name:   "leaf",
+		Path:   "umbrella/charts/leaf",
+		Reason: ChangeReasonChanged,
+	}}
+	res, _, err := RollUpSubcharts(t.Context(), dir, changed, []string{"/umbrella"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"umbrella/charts/leaf changed : "}
+	if actual := describeChangedCharts(dir, res); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected changed charts %q, got %q", expected, actual)
+	}
+}
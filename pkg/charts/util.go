@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 
 	"github.com/sapcc/go-bits/osext"
 	helm_env "k8s.io/helm/pkg/helm/environment"
@@ -21,7 +22,7 @@ func EnsureFileExists(absPath, filename string) (*os.File, error) {
 	}
 
 	filepath := path.Join(absPath, filename)
-	fmt.Println("Using file: ", filepath)
+	fmt.Fprintln(os.Stderr, "Using file: ", filepath)
 
 	f, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE, 0755)
 	if err != nil {
@@ -35,3 +36,21 @@ func EnsureFileExists(absPath, filename string) (*os.File, error) {
 func GetHelmHome() helmpath.Home {
 	return helmpath.Home(osext.GetenvOrDefault("HELM_HOME", helm_env.DefaultHelmHome))
 }
+
+// FindRepositoryRoot returns the closest directory containing the given folder with a .git or an empty string
+// if the folder is not part of a git repository.
+func FindRepositoryRoot(folder string) string {
+	dir, err := filepath.Abs(folder)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+		dir = filepath.Dir(dir)
+	}
+}
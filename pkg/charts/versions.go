@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company
+// SPDX-License-Identifier: Apache-2.0
+
+package charts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Masterminds/semver"
+)
+
+// VersionBumpStatus describes how the version of a changed chart relates to the one at the merge base.
+type VersionBumpStatus string
+
+const (
+	// VersionBumped is used if the version was increased.
+	VersionBumped VersionBumpStatus = "bumped"
+	// VersionNotBumped is used if the chart was changed but the version was not increased.
+	VersionNotBumped VersionBumpStatus = "not-bumped"
+	// VersionNewChart is used for charts that did not exist at the merge base.
+	VersionNewChart VersionBumpStatus = "new"
+)
+
+// VersionBump is the result of comparing the version of a changed chart at the merge base and at the commit.
+type VersionBump struct {
+	Chart *HelmChart
+	// BaseVersion is the version at the merge base. Nil for new charts.
+	BaseVersion *semver.Version
+	Status      VersionBumpStatus
+}
+
+// CheckVersionBumpsInFolder lists the changed Helm charts like ListChangedHelmChartsInFolder
+// and compares their version at the merge base with the version at the given commit.
+// Warnings are printed to stderr. See CheckVersionBumps for a variant that returns them and can be cancelled.
+func CheckVersionBumpsInFolder(opts GitOptions, rootDirectory string, excludeDirs []string, remote, branch, commit string, isUseRelativePath bool) ([]*VersionBump, error) {
+	ctx := context.Background()
+	changed, err := listChangedHelmCharts(ctx, opts, rootDirectory, excludeDirs, remote, branch, commit, false)
+	if err != nil {
+		return nil, err
+	}
+	printWarnings(changed.warnings)
+
+	return checkVersionBumps(ctx, changed, rootDirectory, isUseRelativePath)
+}
+
+func checkVersionBumps(ctx context.Context, changed *changeSet, rootDirectory string, isUseRelativePath bool) ([]*VersionBump, error) {
+	res := make([]*VersionBump, 0, len(changed.charts))
+	for _, c := range changed.charts {
+		chartFile := filepath.Join(c.Path, chartMetadataName)
+
+		version, err := readChartVersion(ctx, changed.git, changed.target, chartFile)
+		if errors.Is(err, errFileNotFound) {
+			// Only changed in the work tree.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Report the version of the commit, which might differ from the one in the work tree.
+		c.Version = version
+
+		bump := &VersionBump{Chart: c}
+		baseVersion, err := readChartVersion(ctx, changed.git, changed.base, chartFile)
+		switch {
+		case errors.Is(err, errFileNotFound):
+			bump.Status = VersionNewChart
+		case err != nil:
+			return nil, err
+		default:
+			bump.BaseVersion = baseVersion
+			bump.Status = VersionNotBumped
+			if version.GreaterThan(baseVersion) {
+				bump.Status = VersionBumped
+			}
+		}
+
+		if isUseRelativePath {
+			relPath, err := filepath.Rel(rootDirectory, c.Path)
+			if err != nil {
+				return nil, err
+			}
+			c.Path = relPath
+		}
+		res = append(res, bump)
+	}
+
+	return res, nil
+}
+
+func readChartVersion(ctx context.Context, git gitBackend, rev, chartFile string) (*semver.Version, error) {
+	data, err := git.readFile(ctx, rev, chartFile)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := parseChartMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", chartFile, rev, err)
+	}
+	return semver.NewVersion(meta.Version)
+}